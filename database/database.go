@@ -2,32 +2,32 @@ package database
 
 import (
 	"database/sql"
-	"log"
+	"log/slog"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
 func InitDB(connectionString string) (*sql.DB, error) {
-    log.Println("Attempting to connect to the database...")
+	slog.Info("connecting to database")
 
-    // Open database
-    db, err := sql.Open("pgx", connectionString)
-    if err != nil {
-        log.Printf("Failed to open database connection: %v", err)
-        return nil, err
-    }
+	// Open database
+	db, err := sql.Open("pgx", connectionString)
+	if err != nil {
+		slog.Error("failed to open database connection", "err", err)
+		return nil, err
+	}
 
-    // Test connection
-    err = db.Ping()
-    if err != nil {
-        log.Printf("Failed to ping database: %v", err)
-        return nil, err
-    }
+	// Test connection
+	err = db.Ping()
+	if err != nil {
+		slog.Error("failed to ping database", "err", err)
+		return nil, err
+	}
 
-    // Set connection pool settings
+	// Set connection pool settings
 	db.SetMaxOpenConns(5)
 	db.SetMaxIdleConns(2)
 
-    log.Println("Database connected successfully")
-    return db, nil
-}
\ No newline at end of file
+	slog.Info("database connected")
+	return db, nil
+}
@@ -0,0 +1,30 @@
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %d has version %d, want contiguous versions from 1", i, m.Version)
+		}
+		if m.Name == "" {
+			t.Errorf("migration %04d has no name", m.Version)
+		}
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			t.Errorf("migration %04d_%s has an empty up or down script", m.Version, m.Name)
+		}
+	}
+	if first := migrations[0]; first.Name != "create_catalogue" {
+		t.Errorf("first migration = %04d_%s, want 0001_create_catalogue", first.Version, first.Name)
+	}
+}
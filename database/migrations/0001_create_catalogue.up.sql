@@ -0,0 +1,18 @@
+-- Adopts databases created before migrations existed, hence IF NOT EXISTS.
+CREATE TABLE IF NOT EXISTS categories (
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS products (
+    id    SERIAL PRIMARY KEY,
+    name  VARCHAR(255) NOT NULL,
+    price INTEGER NOT NULL,
+    stock INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0)
+);
+
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS category_id INTEGER REFERENCES categories (id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_products_category_id ON products (category_id);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS transactions (
+    id           SERIAL PRIMARY KEY,
+    total_amount INTEGER NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_created_at ON transactions (created_at);
+
+CREATE TABLE IF NOT EXISTS transaction_details (
+    id             SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+    product_id     INTEGER NOT NULL REFERENCES products (id),
+    product_name   VARCHAR(255) NOT NULL,
+    quantity       INTEGER NOT NULL CHECK (quantity > 0),
+    price          INTEGER NOT NULL,
+    subtotal       INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_details_transaction_id ON transaction_details (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_details_product_id ON transaction_details (product_id);
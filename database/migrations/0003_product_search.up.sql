@@ -0,0 +1,7 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Trigram index serves ILIKE '%term%' and word_similarity (<%) lookups.
+CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (name gin_trgm_ops);
+
+-- Full-text index on whole words; 'simple' avoids stemming product names.
+CREATE INDEX IF NOT EXISTS idx_products_name_tsv ON products USING GIN (to_tsvector('simple', name));
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_products_barcode;
+DROP INDEX IF EXISTS idx_products_sku;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS barcode,
+    DROP COLUMN IF EXISTS sku;
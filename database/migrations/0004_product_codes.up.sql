@@ -0,0 +1,7 @@
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS sku     VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS barcode VARCHAR(13);
+
+-- Both codes are optional; NULLs do not collide in a unique index.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_sku ON products (sku);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_barcode ON products (barcode);
@@ -0,0 +1,7 @@
+-- Accounts created before roles existed had full access, so they become
+-- admins; new accounts default to the least privileged role.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'admin'
+        CHECK (role IN ('cashier', 'supervisor', 'admin'));
+
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'cashier';
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+    id         BIGSERIAL PRIMARY KEY,
+    actor_id   INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    actor      VARCHAR(64) NOT NULL,
+    action     VARCHAR(20) NOT NULL,
+    entity     VARCHAR(20) NOT NULL,
+    entity_id  INTEGER NOT NULL,
+    before     JSONB,
+    after      JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log (entity, entity_id, id);
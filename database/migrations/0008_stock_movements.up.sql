@@ -0,0 +1,24 @@
+-- Every change to products.stock is recorded here. products.stock stays as
+-- the running balance so reads need no aggregation; each movement stores
+-- the balance it produced, and the movements of a product sum to its stock.
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id             BIGSERIAL PRIMARY KEY,
+    product_id     INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    kind           VARCHAR(20) NOT NULL
+        CHECK (kind IN ('sale', 'restock', 'adjustment', 'return', 'wastage')),
+    quantity       INTEGER NOT NULL CHECK (quantity <> 0),
+    balance        INTEGER NOT NULL CHECK (balance >= 0),
+    reason         TEXT NOT NULL DEFAULT '',
+    transaction_id INTEGER REFERENCES transactions (id) ON DELETE SET NULL,
+    actor_id       INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    actor          VARCHAR(64) NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product ON stock_movements (product_id, id);
+
+-- Open the ledger with the stock on hand today.
+INSERT INTO stock_movements (product_id, kind, quantity, balance, reason, actor)
+SELECT id, 'adjustment', stock, stock, 'opening balance', 'system'
+FROM products
+WHERE stock <> 0;
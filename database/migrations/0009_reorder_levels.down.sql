@@ -0,0 +1,3 @@
+ALTER TABLE products
+    DROP COLUMN IF EXISTS reorder_qty,
+    DROP COLUMN IF EXISTS reorder_level;
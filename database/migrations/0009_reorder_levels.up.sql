@@ -0,0 +1,5 @@
+-- A product is low on stock once stock falls below reorder_level; 0 turns
+-- the alert off. reorder_qty is the suggested quantity to order.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS reorder_level INTEGER NOT NULL DEFAULT 0 CHECK (reorder_level >= 0),
+    ADD COLUMN IF NOT EXISTS reorder_qty   INTEGER NOT NULL DEFAULT 0 CHECK (reorder_qty >= 0);
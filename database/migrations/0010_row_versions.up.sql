@@ -0,0 +1,4 @@
+-- version is bumped by every write and serves as the ETag, so clients can
+-- send If-Match and have concurrent edits rejected instead of lost.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,9 @@
+-- Rows deleted since the upgrade come back as live rows. Recreating the
+-- full unique indexes fails if a deleted product's code has been reused.
+DROP INDEX IF EXISTS idx_products_barcode;
+DROP INDEX IF EXISTS idx_products_sku;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_sku ON products (sku);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_barcode ON products (barcode);
+
+ALTER TABLE categories DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE products DROP COLUMN IF EXISTS deleted_at;
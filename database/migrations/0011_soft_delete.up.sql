@@ -0,0 +1,11 @@
+-- Deleting a product or category now stamps deleted_at instead of removing
+-- the row, so sales and the stock ledger keep pointing at it.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- A deleted product gives up its codes, so they only need to be unique
+-- among live products.
+DROP INDEX IF EXISTS idx_products_sku;
+DROP INDEX IF EXISTS idx_products_barcode;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_sku ON products (sku) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_barcode ON products (barcode) WHERE deleted_at IS NULL;
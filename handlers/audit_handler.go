@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+)
+
+type AuditHandler struct {
+	service *services.AuditService
+}
+
+func NewAuditHandler(service *services.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// Routes registers the audit log endpoint on mux.
+func (h *AuditHandler) Routes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/audit", h.List)
+}
+
+// List godoc
+// @Summary      List audit log entries
+// @Description  Catalogue changes with before and after snapshots, newest first unless sorted otherwise
+// @Tags         audit
+// @Produce      json
+// @Security     BearerAuth
+// @Param        entity  query      string         false  "product or category"
+// @Param        id      query      int            false  "Entity ID; requires entity"
+// @Param        page    query      int            false  "Page number, from 1 to 10000"
+// @Param        limit   query      int            false  "Page size, at most 100"
+// @Param        sort    query      string         false  "Comma-separated id or created_at; prefix - for descending"
+// @Success      200     {array}    models.AuditEntry
+// @Header       200     {integer}  X-Total-Count  "Total matching entries"
+// @Failure      400     {object}   ErrorResponse
+// @Failure      401     {object}   ErrorResponse
+// @Failure      403     {object}   ErrorResponse
+// @Failure      422     {object}   ErrorResponse
+// @Router       /api/audit [get]
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, models.PermAuditRead) {
+		return
+	}
+
+	q := r.URL.Query()
+	opts, err := parseListOptions(q)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+	entityID, err := optionalInt(q, "id")
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+
+	entries, total, err := h.service.List(r.Context(), models.AuditFilter{
+		ListOptions: opts,
+		Entity:      q.Get("entity"),
+		EntityID:    entityID,
+	})
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	setTotalCount(w, total)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+)
+
+type AuthHandler struct {
+	service *services.AuthService
+}
+
+func NewAuthHandler(service *services.AuthService) *AuthHandler {
+	return &AuthHandler{service: service}
+}
+
+// Routes registers the login, refresh and logout endpoints on mux. They
+// must stay reachable without a token.
+func (h *AuthHandler) Routes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/auth/login", h.Login)
+	mux.HandleFunc("POST /api/auth/refresh", h.Refresh)
+	mux.HandleFunc("POST /api/auth/logout", h.Logout)
+}
+
+// Login godoc
+// @Summary      Log in
+// @Description  Exchange a username and password for an access and refresh token
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        credentials  body      models.LoginRequest  true  "Username and password"
+// @Success      200          {object}  models.TokenPair
+// @Failure      400          {object}  ErrorResponse
+// @Failure      401          {object}  ErrorResponse
+// @Failure      422          {object}  ErrorResponse
+// @Router       /api/auth/login [post]
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req models.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid request body")
+		return
+	}
+
+	tokens, err := h.service.Login(r.Context(), &req)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// Refresh godoc
+// @Summary      Refresh tokens
+// @Description  Exchange a refresh token for a new token pair. Each refresh token works once.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        token  body      models.RefreshRequest  true  "Refresh token"
+// @Success      200    {object}  models.TokenPair
+// @Failure      400    {object}  ErrorResponse
+// @Failure      401    {object}  ErrorResponse
+// @Failure      422    {object}  ErrorResponse
+// @Router       /api/auth/refresh [post]
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req models.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid request body")
+		return
+	}
+
+	tokens, err := h.service.Refresh(r.Context(), &req)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// Logout godoc
+// @Summary      Log out
+// @Description  Revoke a refresh token
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        token  body      models.RefreshRequest  true  "Refresh token"
+// @Success      200    {object}  map[string]string
+// @Failure      400    {object}  ErrorResponse
+// @Router       /api/auth/logout [post]
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req models.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid request body")
+		return
+	}
+
+	if err := h.service.Logout(r.Context(), &req); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Logged out successfully",
+	})
+}
@@ -2,11 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"kasir-api/models"
 	"kasir-api/services"
 	"net/http"
 	"strconv"
-	"strings"
 )
 
 type CategoryHandler struct {
@@ -17,117 +17,344 @@ func NewCategoryHandler(service *services.CategoryService) *CategoryHandler {
 	return &CategoryHandler{service: service}
 }
 
-// HandleCategories - GET/POST /api/categories
-func (h *CategoryHandler) HandleCategories(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		h.GetAll(w, r)
-	case http.MethodPost:
-		h.Create(w, r)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	}
+// Routes registers the category endpoints on mux.
+func (h *CategoryHandler) Routes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/categories", h.GetAll)
+	mux.HandleFunc("POST /api/categories", h.Create)
+	mux.HandleFunc("GET /api/categories/{id}", h.GetByID)
+	mux.HandleFunc("PUT /api/categories/{id}", h.Update)
+	mux.HandleFunc("PATCH /api/categories/{id}", h.Patch)
+	mux.HandleFunc("DELETE /api/categories/{id}", h.Delete)
+	mux.HandleFunc("GET /api/categories/{id}/products", h.GetProducts)
+	mux.HandleFunc("POST /api/categories/{id}/restore", h.Restore)
 }
 
+// GetAll godoc
+// @Summary      Get all categories
+// @Description  List categories with sorting and pagination. The unpaged total is sent in X-Total-Count. Deleted categories are left out unless an admin asks for include_deleted=true.
+// @Tags         categories
+// @Produce      json
+// @Security     BearerAuth
+// @Param        include_deleted  query      bool           false  "Also list deleted categories (admin only)"
+// @Param        page             query      int            false  "Page number, from 1 to 10000"
+// @Param        limit            query      int            false  "Page size, at most 100"
+// @Param        sort             query      string         false  "Comma-separated id or name; prefix - for descending"
+// @Success      200              {array}    models.Category
+// @Header       200              {integer}  X-Total-Count  "Total categories"
+// @Failure      400              {object}   ErrorResponse
+// @Failure      401              {object}   ErrorResponse
+// @Failure      403              {object}   ErrorResponse
+// @Failure      422              {object}   ErrorResponse
+// @Router       /api/categories [get]
 func (h *CategoryHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	categories, err := h.service.GetAll()
+	if !authorize(w, r, models.PermCategoryRead) {
+		return
+	}
+
+	opts, err := parseListOptions(r.URL.Query())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+	deleted, ok := includeDeleted(w, r, models.PermCategoryDelete)
+	if !ok {
 		return
 	}
 
+	filter := models.CategoryFilter{ListOptions: opts, IncludeDeleted: deleted}
+	categories, total, err := h.service.GetAll(r.Context(), filter)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	setTotalCount(w, total)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(categories)
 }
 
+// Create godoc
+// @Summary      Create new category
+// @Description  Add a new category
+// @Tags         categories
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        category  body      models.Category  true  "Category data"
+// @Success      201       {object}  models.Category
+// @Header       201       {string}  ETag             "Version of the new category"
+// @Failure      400       {object}  ErrorResponse
+// @Failure      401       {object}  ErrorResponse
+// @Failure      403       {object}  ErrorResponse
+// @Failure      409       {object}  ErrorResponse
+// @Failure      422       {object}  ErrorResponse
+// @Router       /api/categories [post]
 func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, models.PermCategoryWrite) {
+		return
+	}
+
 	var category models.Category
 	err := json.NewDecoder(r.Body).Decode(&category)
 	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid request body")
 		return
 	}
 
-	err = h.service.Create(&category)
+	err = h.service.Create(r.Context(), &category, actorFrom(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, r, err)
 		return
 	}
 
+	setETag(w, category.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(category)
 }
 
-// HandleCategoryByID - GET/PUT/DELETE /api/categories/{id}
-func (h *CategoryHandler) HandleCategoryByID(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		h.GetByID(w, r)
-	case http.MethodPut:
-		h.Update(w, r)
-	case http.MethodDelete:
-		h.Delete(w, r)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// GetByID godoc
+// @Summary      Get category by ID
+// @Description  Get single category by ID. A deleted category is 404 unless an admin asks for include_deleted=true.
+// @Tags         categories
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id               path      int   true   "Category ID"
+// @Param        include_deleted  query     bool  false  "Also find a deleted category (admin only)"
+// @Success      200              {object}  models.Category
+// @Header       200              {string}  ETag  "Current version, for If-Match"
+// @Failure      400              {object}  ErrorResponse
+// @Failure      401              {object}  ErrorResponse
+// @Failure      403              {object}  ErrorResponse
+// @Failure      404              {object}  ErrorResponse
+// @Router       /api/categories/{id} [get]
+func (h *CategoryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, models.PermCategoryRead) {
+		return
 	}
-}
 
-func (h *CategoryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/categories/")
-	id, err := strconv.Atoi(idStr)
+	id, err := strconv.Atoi(r.PathValue("id"))
 	if err != nil {
-		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid category ID")
+		return
+	}
+	deleted, ok := includeDeleted(w, r, models.PermCategoryDelete)
+	if !ok {
 		return
 	}
 
-	category, err := h.service.GetByID(id)
+	category, err := h.service.GetByID(r.Context(), id, deleted)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeServiceError(w, r, err)
 		return
 	}
 
+	setETag(w, category.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(category)
 }
 
+// GetProducts godoc
+// @Summary      Get products in a category
+// @Description  List the products of one category, with the same filters as GET /api/produk
+// @Tags         categories
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path       int            true   "Category ID"
+// @Param        name       query      string         false  "Name contains (case-insensitive)"
+// @Param        min_price  query      int            false  "Minimum price"
+// @Param        max_price  query      int            false  "Maximum price"
+// @Param        in_stock   query      bool           false  "Only products with (true) or without (false) stock"
+// @Param        page       query      int            false  "Page number, from 1 to 10000"
+// @Param        limit      query      int            false  "Page size, at most 100"
+// @Param        sort       query      string         false  "Comma-separated id, name, price or stock; prefix - for descending"
+// @Success      200        {array}    models.Product
+// @Header       200        {integer}  X-Total-Count  "Total matching products"
+// @Failure      400        {object}   ErrorResponse
+// @Failure      401        {object}   ErrorResponse
+// @Failure      403        {object}   ErrorResponse
+// @Failure      404        {object}   ErrorResponse
+// @Failure      422        {object}   ErrorResponse
+// @Router       /api/categories/{id}/products [get]
+func (h *CategoryHandler) GetProducts(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, models.PermProductRead) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid category ID")
+		return
+	}
+
+	filter, err := parseProductFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+
+	products, total, err := h.service.GetProducts(r.Context(), id, filter)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	setTotalCount(w, total)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(products)
+}
+
+// Update godoc
+// @Summary      Update category
+// @Description  Replace a category by ID. If-Match must carry the ETag from GET; 412 means someone changed the category since.
+// @Tags         categories
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      int              true  "Category ID"
+// @Param        If-Match  header    string           true  "ETag from GET, or *"
+// @Param        category  body      models.Category  true  "Category data"
+// @Success      200       {object}  models.Category
+// @Header       200       {string}  ETag             "New version"
+// @Failure      400       {object}  ErrorResponse
+// @Failure      401       {object}  ErrorResponse
+// @Failure      403       {object}  ErrorResponse
+// @Failure      404       {object}  ErrorResponse
+// @Failure      409       {object}  ErrorResponse
+// @Failure      412       {object}  ErrorResponse
+// @Failure      422       {object}  ErrorResponse
+// @Failure      428       {object}  ErrorResponse
+// @Router       /api/categories/{id} [put]
 func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/categories/")
-	id, err := strconv.Atoi(idStr)
+	if !authorize(w, r, models.PermCategoryWrite) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
 	if err != nil {
-		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid category ID")
+		return
+	}
+	version, ok := ifMatch(w, r)
+	if !ok {
 		return
 	}
 
 	var category models.Category
 	err = json.NewDecoder(r.Body).Decode(&category)
 	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid request body")
 		return
 	}
 
 	category.ID = id
-	err = h.service.Update(&category)
+	category.Version = version
+	err = h.service.Update(r.Context(), &category, actorFrom(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, r, err)
 		return
 	}
 
+	setETag(w, category.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(category)
 }
 
+// Patch godoc
+// @Summary      Patch category
+// @Description  Change some fields of a category with a JSON Merge Patch (RFC 7396). Fields left out keep their value; null is not accepted. If-Match must carry the ETag from GET.
+// @Tags         categories
+// @Accept       json,application/merge-patch+json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      int              true  "Category ID"
+// @Param        If-Match  header    string           true  "ETag from GET, or *"
+// @Param        patch     body      models.Category  true  "Fields to change"
+// @Success      200       {object}  models.Category
+// @Header       200       {string}  ETag             "New version"
+// @Failure      400       {object}  ErrorResponse
+// @Failure      401       {object}  ErrorResponse
+// @Failure      403       {object}  ErrorResponse
+// @Failure      404       {object}  ErrorResponse
+// @Failure      409       {object}  ErrorResponse
+// @Failure      412       {object}  ErrorResponse
+// @Failure      415       {object}  ErrorResponse
+// @Failure      422       {object}  ErrorResponse
+// @Failure      428       {object}  ErrorResponse
+// @Router       /api/categories/{id} [patch]
+func (h *CategoryHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, models.PermCategoryWrite) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid category ID")
+		return
+	}
+	version, ok := ifMatch(w, r)
+	if !ok {
+		return
+	}
+	body, ok := readMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	category, err := h.service.Patch(r.Context(), id, version, body, actorFrom(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	setETag(w, category.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(category)
+}
+
+// Delete godoc
+// @Summary      Delete category
+// @Description  Soft-delete a category by ID; it can be restored later. If-Match must carry the ETag from GET. Fails with 409 while products use it, unless cascade=true also deletes them.
+// @Tags         categories
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      int     true   "Category ID"
+// @Param        If-Match  header    string  true   "ETag from GET, or *"
+// @Param        cascade   query     bool    false  "Also delete the category's products"
+// @Success      200       {object}  map[string]string
+// @Failure      400       {object}  ErrorResponse
+// @Failure      401       {object}  ErrorResponse
+// @Failure      403       {object}  ErrorResponse
+// @Failure      404       {object}  ErrorResponse
+// @Failure      409       {object}  ErrorResponse
+// @Failure      412       {object}  ErrorResponse
+// @Failure      428       {object}  ErrorResponse
+// @Router       /api/categories/{id} [delete]
 func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/categories/")
-	id, err := strconv.Atoi(idStr)
+	if !authorize(w, r, models.PermCategoryDelete) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
 	if err != nil {
-		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid category ID")
+		return
+	}
+	version, ok := ifMatch(w, r)
+	if !ok {
 		return
 	}
 
-	err = h.service.Delete(id)
+	cascade := false
+	if v := r.URL.Query().Get("cascade"); v != "" {
+		if cascade, err = strconv.ParseBool(v); err != nil {
+			writeError(w, r, http.StatusBadRequest, "bad_request", fmt.Sprintf("invalid cascade %q", v))
+			return
+		}
+	}
+	err = h.service.Delete(r.Context(), id, version, cascade, actorFrom(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 		return
 	}
 
@@ -136,3 +363,40 @@ func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		"message": "Category deleted successfully",
 	})
 }
+
+// Restore godoc
+// @Summary      Restore category
+// @Description  Bring back a deleted category along with the products its cascading delete removed. Fails with 409 if it is not deleted or a restored product's SKU or barcode has since been reused.
+// @Tags         categories
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int   true  "Category ID"
+// @Success      200  {object}  models.Category
+// @Header       200  {string}  ETag  "Version of the restored category"
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Router       /api/categories/{id}/restore [post]
+func (h *CategoryHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, models.PermCategoryDelete) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid category ID")
+		return
+	}
+
+	category, err := h.service.Restore(r.Context(), id, actorFrom(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	setETag(w, category.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(category)
+}
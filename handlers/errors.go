@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"kasir-api/logging"
+	"kasir-api/repositories"
+	"kasir-api/services"
+	"log/slog"
+	"net/http"
+)
+
+// ErrorResponse is the JSON body of every non-2xx response.
+type ErrorResponse struct {
+	Code      string            `json:"code"`
+	Message   string            `json:"message"`
+	RequestID string            `json:"request_id,omitempty"`
+	Errors    map[string]string `json:"errors,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	writeJSON(w, status, ErrorResponse{
+		Code:      code,
+		Message:   message,
+		RequestID: logging.RequestID(r.Context()),
+	})
+}
+
+// statusClientClosedRequest is the non-standard status (borrowed from nginx)
+// recorded when the client disconnects before the reply is ready.
+const statusClientClosedRequest = 499
+
+// writeServiceError maps an error from the service layer onto a status code
+// via the repository sentinels. A request whose context deadline expired is
+// answered with 504. Unrecognised errors are logged and reported as a
+// generic 500 so database details never reach the client.
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	var verr *services.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		writeJSON(w, http.StatusUnprocessableEntity, ErrorResponse{
+			Code:      "validation_failed",
+			Message:   "request failed validation",
+			RequestID: logging.RequestID(r.Context()),
+			Errors:    verr.Fields,
+		})
+	case errors.Is(err, services.ErrUnauthorized):
+		writeError(w, r, http.StatusUnauthorized, "unauthorized", err.Error())
+	case errors.Is(err, repositories.ErrValidation):
+		writeError(w, r, http.StatusUnprocessableEntity, "validation_failed", err.Error())
+	case errors.Is(err, repositories.ErrNotFound):
+		writeError(w, r, http.StatusNotFound, "not_found", err.Error())
+	case errors.Is(err, repositories.ErrVersionMismatch):
+		writeError(w, r, http.StatusPreconditionFailed, "precondition_failed", err.Error())
+	case errors.Is(err, repositories.ErrConflict):
+		writeError(w, r, http.StatusConflict, "conflict", err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		writeError(w, r, http.StatusGatewayTimeout, "timeout", "the database did not respond in time")
+	case errors.Is(err, context.Canceled):
+		writeError(w, r, statusClientClosedRequest, "client_closed_request", "request cancelled by client")
+	default:
+		slog.ErrorContext(r.Context(), "request failed", "method", r.Method, "path", r.URL.Path, "err", err)
+		writeError(w, r, http.StatusInternalServerError, "internal_error", "internal server error")
+	}
+}
+
+// JSONFallback wraps mux so requests it has no route for get the JSON
+// error envelope instead of ServeMux's plain-text 404 and 405 replies. The
+// Allow header ServeMux computes for a 405 is passed through.
+func JSONFallback(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := mux.Handler(r); pattern != "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		// Let the mux decide between 404 and 405 without writing anything.
+		probe := &statusProbe{header: make(http.Header)}
+		mux.ServeHTTP(probe, r)
+		if probe.status == http.StatusMethodNotAllowed {
+			w.Header().Set("Allow", probe.header.Get("Allow"))
+			writeError(w, r, http.StatusMethodNotAllowed, "method_not_allowed", "method "+r.Method+" not allowed")
+			return
+		}
+		writeError(w, r, http.StatusNotFound, "not_found", "no route for "+r.URL.Path)
+	})
+}
+
+// statusProbe is a ResponseWriter that records the status and headers and
+// discards the body.
+type statusProbe struct {
+	header http.Header
+	status int
+}
+
+func (p *statusProbe) Header() http.Header         { return p.header }
+func (p *statusProbe) Write(b []byte) (int, error) { return len(b), nil }
+func (p *statusProbe) WriteHeader(status int)      { p.status = status }
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+)
+
+type HealthHandler struct {
+	service *services.HealthService
+}
+
+func NewHealthHandler(service *services.HealthService) *HealthHandler {
+	return &HealthHandler{service: service}
+}
+
+// Routes registers the health endpoints on mux. /api/health is kept as an
+// alias of the liveness probe for existing monitors.
+func (h *HealthHandler) Routes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/health", h.Live)
+	mux.HandleFunc("GET /api/health/live", h.Live)
+	mux.HandleFunc("GET /api/health/ready", h.Ready)
+}
+
+// Live godoc
+// @Summary      Liveness probe
+// @Description  Reports that the process is up, without checking dependencies
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  models.HealthReport
+// @Router       /api/health/live [get]
+// @Router       /api/health [get]
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(h.service.Live())
+}
+
+// Ready godoc
+// @Summary      Readiness probe
+// @Description  Pings the database, checks the schema version and reports pool stats
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  models.HealthReport
+// @Failure      503  {object}  models.HealthReport
+// @Router       /api/health/ready [get]
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	report := h.service.Ready(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status != models.HealthOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
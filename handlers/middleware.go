@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"kasir-api/logging"
+	"kasir-api/metrics"
+	"kasir-api/models"
+	"kasir-api/services"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type contextKey int
+
+const claimsKey contextKey = 0
+
+// RequestID tags each request with an ID, reusing a sane incoming
+// X-Request-ID header or generating one, and echoes it on the response. The
+// ID is read back with logging.RequestID.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" || len(id) > 128 {
+			id = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// AccessLog logs one line per request with its method, path, status,
+// response size and latency. Server errors are logged at error level.
+func AccessLog(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			level := slog.LevelInfo
+			if rec.status >= 500 {
+				level = slog.LevelError
+			}
+			logger.LogAttrs(r.Context(), level, "request",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", rec.status),
+				slog.Int("bytes", rec.bytes),
+				slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.String("remote_addr", r.RemoteAddr),
+			)
+		})
+	}
+}
+
+// Metrics records each request in the Prometheus HTTP metrics, labelled by
+// the route pattern rather than the raw path so IDs do not multiply the
+// series. The route is looked up on mux up front, so Metrics can sit
+// outside Authenticate and still label the 401s it sends.
+func Metrics(mux *http.ServeMux) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			_, pattern := mux.Handler(r)
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			// Every route is registered with a method, so a pattern without
+			// one is the target path of a ServeMux redirect, which is
+			// unbounded.
+			route := "unmatched"
+			if _, path, ok := strings.Cut(pattern, " "); ok {
+				route = path
+			} else if pattern != "" {
+				route = "redirect"
+			}
+			status := strconv.Itoa(rec.status)
+			metrics.HTTPRequests.WithLabelValues(r.Method, route, status).Inc()
+			metrics.HTTPDuration.WithLabelValues(r.Method, route, status).Observe(time.Since(start).Seconds())
+		})
+	}
+}
+
+// statusRecorder remembers the status code and body size written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if !s.wroteHeader {
+		s.status = status
+		s.wroteHeader = true
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	s.wroteHeader = true
+	n, err := s.ResponseWriter.Write(b)
+	s.bytes += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// Timeout bounds the database work of each request: the request context is
+// cancelled after d, aborting any query still running, and the handler
+// answers 504. A zero d leaves requests unbounded.
+func Timeout(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if d <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Authenticate returns middleware that requires an
+// "Authorization: Bearer <access token>" header and makes the token's claims
+// available through ClaimsFromContext. A missing, malformed or invalid token
+// is rejected with 401 unless the request matches one of the public mux
+// patterns, which are served without looking at the header at all. Routes
+// are closed by default, so a handler that forgets authorize still cannot
+// be reached anonymously.
+func Authenticate(auth *services.AuthService, mux *http.ServeMux, public ...string) func(http.Handler) http.Handler {
+	open := make(map[string]bool, len(public))
+	for _, pattern := range public {
+		open[pattern] = true
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, pattern := mux.Handler(r); open[pattern] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="kasir-api"`)
+				writeError(w, r, http.StatusUnauthorized, "unauthorized", "missing bearer token")
+				return
+			}
+
+			token, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok || token == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="kasir-api", error="invalid_request"`)
+				writeError(w, r, http.StatusUnauthorized, "unauthorized", "malformed bearer token")
+				return
+			}
+
+			claims, err := auth.Authenticate(token)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="kasir-api", error="invalid_token"`)
+				writeError(w, r, http.StatusUnauthorized, "unauthorized", "invalid or expired token")
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), claimsKey, claims)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// authorize answers 403 unless the authenticated user's role grants perm,
+// and reports whether the request may proceed. Every handler behind
+// Authenticate calls it first; the 401 for a request without claims only
+// guards against a handler being mounted on a public route by mistake.
+func authorize(w http.ResponseWriter, r *http.Request, perm models.Permission) bool {
+	claims := ClaimsFromContext(r.Context())
+	if claims == nil {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="kasir-api"`)
+		writeError(w, r, http.StatusUnauthorized, "unauthorized", "missing bearer token")
+		return false
+	}
+	if !claims.Can(perm) {
+		writeError(w, r, http.StatusForbidden, "forbidden", "role "+string(claims.Role)+" lacks permission "+string(perm))
+		return false
+	}
+	return true
+}
+
+// RequireToken wraps a handler scraped by machines, such as the Prometheus
+// exporter, so only requests carrying "Authorization: Bearer <token>" reach
+// it. Scrapers cannot log in for short-lived access tokens, so the route is
+// listed as public for Authenticate and guarded by this static credential
+// instead.
+func RequireToken(token string, h http.Handler) http.Handler {
+	want := []byte(token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="kasir-api"`)
+			writeError(w, r, http.StatusUnauthorized, "unauthorized", "invalid scrape token")
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// actorFrom identifies the authenticated user for the audit log.
+func actorFrom(r *http.Request) models.Actor {
+	claims := ClaimsFromContext(r.Context())
+	if claims == nil {
+		return models.Actor{Username: "anonymous"}
+	}
+	return models.Actor{ID: claims.UserID(), Username: claims.Username}
+}
+
+// ClaimsFromContext returns the claims stored by Authenticate, or nil.
+func ClaimsFromContext(ctx context.Context) *services.Claims {
+	claims, _ := ctx.Value(claimsKey).(*services.Claims)
+	return claims
+}
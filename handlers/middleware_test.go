@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"kasir-api/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAuthorize(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if authorize(w, r, models.PermProductDelete) {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	tests := []struct {
+		name   string
+		claims *services.Claims
+		want   int
+	}{
+		{"no claims", nil, http.StatusUnauthorized},
+		{"cashier", &services.Claims{Role: models.RoleCashier}, http.StatusForbidden},
+		{"supervisor", &services.Claims{Role: models.RoleSupervisor}, http.StatusForbidden},
+		{"admin", &services.Claims{Role: models.RoleAdmin}, http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodDelete, "/api/produk/1", nil)
+			if tt.claims != nil {
+				r = r.WithContext(context.WithValue(r.Context(), claimsKey, tt.claims))
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	auth := services.NewAuthService(repositories.NewMemoryStore().Users(), services.AuthConfig{
+		Secret:     []byte("0123456789abcdef0123456789abcdef"),
+		AccessTTL:  time.Minute,
+		RefreshTTL: time.Hour,
+	})
+	if err := auth.EnsureAdmin(ctx, "admin", "password1"); err != nil {
+		t.Fatal(err)
+	}
+	pair, err := auth.Login(ctx, &models.LoginRequest{Username: "admin", Password: "password1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	mux.Handle("GET /api/health", ok)
+	mux.Handle("GET /api/produk", ok)
+	h := Authenticate(auth, mux, "GET /api/health")(mux)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header string
+		want   int
+	}{
+		{"public route", http.MethodGet, "/api/health", "", http.StatusNoContent},
+		{"public route ignores a bad token", http.MethodGet, "/api/health", "Bearer junk", http.StatusNoContent},
+		{"protected route without a token", http.MethodGet, "/api/produk", "", http.StatusUnauthorized},
+		{"protected route with a bad token", http.MethodGet, "/api/produk", "Bearer junk", http.StatusUnauthorized},
+		{"malformed header", http.MethodGet, "/api/produk", "Basic " + pair.AccessToken, http.StatusUnauthorized},
+		{"protected route with a token", http.MethodGet, "/api/produk", "Bearer " + pair.AccessToken, http.StatusNoContent},
+		{"unknown route", http.MethodGet, "/api/secret", "", http.StatusUnauthorized},
+		{"public path, other method", http.MethodPost, "/api/health", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireToken(t *testing.T) {
+	h := RequireToken("scrape-secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	tests := []struct {
+		header string
+		want   int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"Bearer scrape-secret-and-more", http.StatusUnauthorized},
+		{"scrape-secret", http.StatusUnauthorized},
+		{"Bearer scrape-secret", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if tt.header != "" {
+			r.Header.Set("Authorization", tt.header)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.want {
+			t.Errorf("Authorization %q: status = %d, want %d", tt.header, w.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"kasir-api/models"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// parseListOptions reads ?page=, ?limit= and ?sort=price,-name. Range
+// checks are left to the service layer; only malformed numbers fail here.
+func parseListOptions(q url.Values) (models.ListOptions, error) {
+	opts := models.ListOptions{Page: 1, Limit: models.DefaultPageLimit}
+
+	var err error
+	if v := q.Get("page"); v != "" {
+		if opts.Page, err = strconv.Atoi(v); err != nil {
+			return opts, fmt.Errorf("invalid page %q", v)
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if opts.Limit, err = strconv.Atoi(v); err != nil {
+			return opts, fmt.Errorf("invalid limit %q", v)
+		}
+	}
+	if v := q.Get("sort"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			desc := strings.HasPrefix(name, "-")
+			name = strings.TrimPrefix(name, "-")
+			if name == "" {
+				return opts, fmt.Errorf("invalid sort %q", v)
+			}
+			opts.Sort = append(opts.Sort, models.SortField{Name: name, Desc: desc})
+		}
+	}
+	return opts, nil
+}
+
+// parseProductFilter reads the list options plus ?name=, ?min_price=,
+// ?max_price=, ?in_stock= and ?category_id=.
+func parseProductFilter(q url.Values) (models.ProductFilter, error) {
+	var f models.ProductFilter
+	var err error
+	if f.ListOptions, err = parseListOptions(q); err != nil {
+		return f, err
+	}
+	f.Name = strings.TrimSpace(q.Get("name"))
+	if f.MinPrice, err = optionalInt(q, "min_price"); err != nil {
+		return f, err
+	}
+	if f.MaxPrice, err = optionalInt(q, "max_price"); err != nil {
+		return f, err
+	}
+	if f.CategoryID, err = optionalInt(q, "category_id"); err != nil {
+		return f, err
+	}
+	if v := q.Get("in_stock"); v != "" {
+		inStock, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid in_stock %q", v)
+		}
+		f.InStock = &inStock
+	}
+	return f, nil
+}
+
+func optionalInt(q url.Values, key string) (*int, error) {
+	v := q.Get(key)
+	if v == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q", key, v)
+	}
+	return &n, nil
+}
+
+// includeDeleted reads ?include_deleted=. Deleted rows are only shown to
+// roles holding perm, the permission that deletes them; anyone else asking
+// for them is answered with 403, as is a malformed value with 400, and ok
+// is false.
+func includeDeleted(w http.ResponseWriter, r *http.Request, perm models.Permission) (include, ok bool) {
+	v := r.URL.Query().Get("include_deleted")
+	if v == "" {
+		return false, true
+	}
+	include, err := strconv.ParseBool(v)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", fmt.Sprintf("invalid include_deleted %q", v))
+		return false, false
+	}
+	if include && !authorize(w, r, perm) {
+		return false, false
+	}
+	return include, true
+}
+
+// setTotalCount exposes the unpaged match count to clients.
+func setTotalCount(w http.ResponseWriter, total int) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+}
+
+// setETag sends a row version as a strong entity tag.
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", `"`+strconv.Itoa(version)+`"`)
+}
+
+// ifMatch reads the version a client last saw from If-Match, so concurrent
+// edits cannot silently overwrite each other. A missing header is answered
+// with 428 and a tag this API never issues (weak, a list, malformed) with
+// 412, and ok is false. "*" yields version 0, which matches any version.
+func ifMatch(w http.ResponseWriter, r *http.Request) (version int, ok bool) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "" {
+		writeError(w, r, http.StatusPreconditionRequired, "precondition_required",
+			"If-Match is required; send the ETag from GET")
+		return 0, false
+	}
+	if v == "*" {
+		return 0, true
+	}
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		if n, err := strconv.Atoi(v[1 : len(v)-1]); err == nil && n > 0 {
+			return n, true
+		}
+	}
+	writeError(w, r, http.StatusPreconditionFailed, "precondition_failed", "If-Match does not match the current version")
+	return 0, false
+}
+
+// mergePatchType is the media type of a JSON Merge Patch (RFC 7396).
+const mergePatchType = "application/merge-patch+json"
+
+// readMergePatch reads a PATCH body, which must be sent as
+// application/merge-patch+json or plain application/json. It answers 415 or
+// 400 itself and returns ok=false when the body cannot be used.
+func readMergePatch(w http.ResponseWriter, r *http.Request) (body []byte, ok bool) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (mediaType != mergePatchType && mediaType != "application/json") {
+		w.Header().Set("Accept-Patch", mergePatchType)
+		writeError(w, r, http.StatusUnsupportedMediaType, "unsupported_media_type",
+			"send the patch as "+mergePatchType)
+		return nil, false
+	}
+	body, err = io.ReadAll(r.Body)
+	if err != nil || !json.Valid(body) {
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid request body")
+		return nil, false
+	}
+	return body, true
+}
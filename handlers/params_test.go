@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		version int
+		ok      bool
+		status  int
+		code    string
+	}{
+		{"strong tag", `"3"`, 3, true, 0, ""},
+		{"padded strong tag", `  "12" `, 12, true, 0, ""},
+		{"wildcard", `*`, 0, true, 0, ""},
+		{"missing", ``, 0, false, http.StatusPreconditionRequired, "precondition_required"},
+		{"weak tag", `W/"3"`, 0, false, http.StatusPreconditionFailed, "precondition_failed"},
+		{"list", `"3", "4"`, 0, false, http.StatusPreconditionFailed, "precondition_failed"},
+		{"unquoted", `3`, 0, false, http.StatusPreconditionFailed, "precondition_failed"},
+		{"not a number", `"abc"`, 0, false, http.StatusPreconditionFailed, "precondition_failed"},
+		{"zero", `"0"`, 0, false, http.StatusPreconditionFailed, "precondition_failed"},
+		{"negative", `"-1"`, 0, false, http.StatusPreconditionFailed, "precondition_failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/api/produk/1", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			version, ok := ifMatch(w, r)
+			if version != tt.version || ok != tt.ok {
+				t.Fatalf("ifMatch(%q) = %d, %v; want %d, %v", tt.header, version, ok, tt.version, tt.ok)
+			}
+			if ok {
+				if w.Body.Len() != 0 {
+					t.Errorf("ifMatch(%q) wrote a response: %s", tt.header, w.Body)
+				}
+				return
+			}
+			if w.Code != tt.status {
+				t.Errorf("ifMatch(%q) status = %d, want %d", tt.header, w.Code, tt.status)
+			}
+			var resp ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode error response: %v", err)
+			}
+			if resp.Code != tt.code {
+				t.Errorf("ifMatch(%q) code = %q, want %q", tt.header, resp.Code, tt.code)
+			}
+		})
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"kasir-api/services"
 	"net/http"
 	"strconv"
-	"strings"
 )
 
 type ProductHandler struct {
@@ -17,119 +16,401 @@ func NewProductHandler(service *services.ProductService) *ProductHandler {
 	return &ProductHandler{service: service}
 }
 
-// HandleProducts - GET /api/produk
-func (h *ProductHandler) HandleProducts(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		h.GetAll(w, r)
-	case http.MethodPost:
-		h.Create(w, r)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	}
+// Routes registers the product endpoints on mux.
+func (h *ProductHandler) Routes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/produk", h.GetAll)
+	mux.HandleFunc("POST /api/produk", h.Create)
+	mux.HandleFunc("GET /api/produk/search", h.Search)
+	mux.HandleFunc("GET /api/produk/low-stock", h.LowStock)
+	mux.HandleFunc("GET /api/produk/by-barcode", h.GetByBarcode)
+	mux.HandleFunc("GET /api/produk/{id}", h.GetByID)
+	mux.HandleFunc("PUT /api/produk/{id}", h.Update)
+	mux.HandleFunc("PATCH /api/produk/{id}", h.Patch)
+	mux.HandleFunc("DELETE /api/produk/{id}", h.Delete)
+	mux.HandleFunc("POST /api/produk/{id}/restore", h.Restore)
 }
 
+// GetAll godoc
+// @Summary      Get all products
+// @Description  List products with optional filters, sorting and pagination. The unpaged total is sent in X-Total-Count. Deleted products are left out unless an admin asks for include_deleted=true.
+// @Tags         produk
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name             query      string         false  "Name contains (case-insensitive)"
+// @Param        min_price        query      int            false  "Minimum price"
+// @Param        max_price        query      int            false  "Maximum price"
+// @Param        in_stock         query      bool           false  "Only products with (true) or without (false) stock"
+// @Param        category_id      query      int            false  "Category ID"
+// @Param        include_deleted  query      bool           false  "Also list deleted products (admin only)"
+// @Param        page             query      int            false  "Page number, from 1 to 10000"
+// @Param        limit            query      int            false  "Page size, at most 100"
+// @Param        sort             query      string         false  "Comma-separated id, name, price, stock or category_id; prefix - for descending"
+// @Success      200              {array}    models.Product
+// @Header       200              {integer}  X-Total-Count  "Total matching products"
+// @Failure      400              {object}   ErrorResponse
+// @Failure      401              {object}   ErrorResponse
+// @Failure      403              {object}   ErrorResponse
+// @Failure      422              {object}   ErrorResponse
+// @Router       /api/produk [get]
 func (h *ProductHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	products, err := h.service.GetAll()
+	if !authorize(w, r, models.PermProductRead) {
+		return
+	}
+
+	filter, err := parseProductFilter(r.URL.Query())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+	var ok bool
+	if filter.IncludeDeleted, ok = includeDeleted(w, r, models.PermProductDelete); !ok {
 		return
 	}
 
+	products, total, err := h.service.GetAll(r.Context(), filter)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	setTotalCount(w, total)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(products)
 }
 
+// Create godoc
+// @Summary      Create new product
+// @Description  Add a new product to the catalogue
+// @Tags         produk
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        product  body      models.Product  true  "Product data"
+// @Success      201      {object}  models.Product
+// @Header       201      {string}  ETag            "Version of the new product"
+// @Failure      400      {object}  ErrorResponse
+// @Failure      401      {object}  ErrorResponse
+// @Failure      403      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse
+// @Failure      422      {object}  ErrorResponse
+// @Router       /api/produk [post]
 func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, models.PermProductWrite) {
+		return
+	}
+
 	var product models.Product
 	err := json.NewDecoder(r.Body).Decode(&product)
 	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid request body")
 		return
 	}
 
-	err = h.service.Create(&product)
+	err = h.service.Create(r.Context(), &product, actorFrom(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, r, err)
 		return
 	}
 
+	setETag(w, product.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(product)
 }
 
-// HandleProductByID - GET/PUT/DELETE /api/produk/{id}
-func (h *ProductHandler) HandleProductByID(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		h.GetByID(w, r)
-	case http.MethodPut:
-		h.Update(w, r)
-	case http.MethodDelete:
-		h.Delete(w, r)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// LowStock godoc
+// @Summary      Low-stock report
+// @Description  Products whose stock is below their reorder level, emptiest first unless sorted otherwise. Takes the same filters as the product list.
+// @Tags         produk
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name         query      string         false  "Name contains (case-insensitive)"
+// @Param        category_id  query      int            false  "Category ID"
+// @Param        page         query      int            false  "Page number, from 1 to 10000"
+// @Param        limit        query      int            false  "Page size, at most 100"
+// @Param        sort         query      string         false  "Comma-separated id, name, price, stock or category_id; prefix - for descending"
+// @Success      200          {array}    models.Product
+// @Header       200          {integer}  X-Total-Count  "Total low-stock products"
+// @Failure      400          {object}   ErrorResponse
+// @Failure      401          {object}   ErrorResponse
+// @Failure      403          {object}   ErrorResponse
+// @Failure      422          {object}   ErrorResponse
+// @Router       /api/produk/low-stock [get]
+func (h *ProductHandler) LowStock(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, models.PermProductRead) {
+		return
+	}
+
+	filter, err := parseProductFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+
+	products, total, err := h.service.LowStock(r.Context(), filter)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
 	}
+
+	setTotalCount(w, total)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(products)
+}
+
+// Search godoc
+// @Summary      Search products by name
+// @Description  Rank products against a partial or misspelt name, best match first
+// @Tags         produk
+// @Produce      json
+// @Security     BearerAuth
+// @Param        q      query     string  true   "Search text"
+// @Param        limit  query     int     false  "Maximum results, at most 100"
+// @Success      200    {array}   models.ProductSearchResult
+// @Failure      400    {object}  ErrorResponse
+// @Failure      401    {object}  ErrorResponse
+// @Failure      403    {object}  ErrorResponse
+// @Failure      422    {object}  ErrorResponse
+// @Router       /api/produk/search [get]
+func (h *ProductHandler) Search(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, models.PermProductRead) {
+		return
+	}
+
+	limit := models.DefaultPageLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "bad_request", "invalid limit")
+			return
+		}
+		limit = n
+	}
+
+	results, err := h.service.Search(r.Context(), r.URL.Query().Get("q"), limit)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// GetByBarcode godoc
+// @Summary      Get product by barcode
+// @Description  Look up a scanned EAN-13 or UPC-A barcode
+// @Tags         produk
+// @Produce      json
+// @Security     BearerAuth
+// @Param        code  query     string  true  "EAN-13 or UPC-A barcode"
+// @Success      200   {object}  models.Product
+// @Failure      401   {object}  ErrorResponse
+// @Failure      403   {object}  ErrorResponse
+// @Failure      404   {object}  ErrorResponse
+// @Failure      422   {object}  ErrorResponse
+// @Router       /api/produk/by-barcode [get]
+func (h *ProductHandler) GetByBarcode(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, models.PermProductRead) {
+		return
+	}
+
+	code := r.URL.Query().Get("code")
+
+	product, err := h.service.GetByBarcode(r.Context(), code)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
 }
 
-// GetByID - GET /api/produk/{id}
+// GetByID godoc
+// @Summary      Get product by ID
+// @Description  Get single product by ID. A deleted product is 404 unless an admin asks for include_deleted=true.
+// @Tags         produk
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id               path      int   true   "Product ID"
+// @Param        include_deleted  query     bool  false  "Also find a deleted product (admin only)"
+// @Success      200              {object}  models.Product
+// @Header       200              {string}  ETag  "Current version, for If-Match"
+// @Failure      400              {object}  ErrorResponse
+// @Failure      401              {object}  ErrorResponse
+// @Failure      403              {object}  ErrorResponse
+// @Failure      404              {object}  ErrorResponse
+// @Router       /api/produk/{id} [get]
 func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/produk/")
-	id, err := strconv.Atoi(idStr)
+	if !authorize(w, r, models.PermProductRead) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid product ID")
+		return
+	}
+	deleted, ok := includeDeleted(w, r, models.PermProductDelete)
+	if !ok {
 		return
 	}
 
-	product, err := h.service.GetByID(id)
+	product, err := h.service.GetByID(r.Context(), id, deleted)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeServiceError(w, r, err)
 		return
 	}
 
+	setETag(w, product.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(product)
 }
 
+// Update godoc
+// @Summary      Update product
+// @Description  Replace a product by ID. If-Match must carry the ETag from GET; 412 means someone changed the product since.
+// @Tags         produk
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      int             true  "Product ID"
+// @Param        If-Match  header    string          true  "ETag from GET, or *"
+// @Param        product   body      models.Product  true  "Product data"
+// @Success      200       {object}  models.Product
+// @Header       200       {string}  ETag            "New version"
+// @Failure      400       {object}  ErrorResponse
+// @Failure      401       {object}  ErrorResponse
+// @Failure      403       {object}  ErrorResponse
+// @Failure      404       {object}  ErrorResponse
+// @Failure      409       {object}  ErrorResponse
+// @Failure      412       {object}  ErrorResponse
+// @Failure      422       {object}  ErrorResponse
+// @Failure      428       {object}  ErrorResponse
+// @Router       /api/produk/{id} [put]
 func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/produk/")
-	id, err := strconv.Atoi(idStr)
+	if !authorize(w, r, models.PermProductWrite) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid product ID")
+		return
+	}
+	version, ok := ifMatch(w, r)
+	if !ok {
 		return
 	}
 
 	var product models.Product
 	err = json.NewDecoder(r.Body).Decode(&product)
 	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid request body")
 		return
 	}
 
 	product.ID = id
-	err = h.service.Update(&product)
+	product.Version = version
+	err = h.service.Update(r.Context(), &product, actorFrom(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	setETag(w, product.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
+
+// Patch godoc
+// @Summary      Patch product
+// @Description  Change some fields of a product with a JSON Merge Patch (RFC 7396). Fields left out keep their value and null clears sku, barcode or category_id; other fields cannot be null. If-Match must carry the ETag from GET.
+// @Tags         produk
+// @Accept       json,application/merge-patch+json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      int             true  "Product ID"
+// @Param        If-Match  header    string          true  "ETag from GET, or *"
+// @Param        patch     body      models.Product  true  "Fields to change"
+// @Success      200       {object}  models.Product
+// @Header       200       {string}  ETag            "New version"
+// @Failure      400       {object}  ErrorResponse
+// @Failure      401       {object}  ErrorResponse
+// @Failure      403       {object}  ErrorResponse
+// @Failure      404       {object}  ErrorResponse
+// @Failure      409       {object}  ErrorResponse
+// @Failure      412       {object}  ErrorResponse
+// @Failure      415       {object}  ErrorResponse
+// @Failure      422       {object}  ErrorResponse
+// @Failure      428       {object}  ErrorResponse
+// @Router       /api/produk/{id} [patch]
+func (h *ProductHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, models.PermProductWrite) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid product ID")
+		return
+	}
+	version, ok := ifMatch(w, r)
+	if !ok {
+		return
+	}
+	body, ok := readMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	product, err := h.service.Patch(r.Context(), id, version, body, actorFrom(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, r, err)
 		return
 	}
 
+	setETag(w, product.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(product)
 }
 
-// Delete - DELETE /api/produk/{id}
+// Delete godoc
+// @Summary      Delete product
+// @Description  Soft-delete a product by ID; it is hidden from reads but kept for sales history and can be restored. If-Match must carry the ETag from GET.
+// @Tags         produk
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      int     true  "Product ID"
+// @Param        If-Match  header    string  true  "ETag from GET, or *"
+// @Success      200       {object}  map[string]string
+// @Failure      400       {object}  ErrorResponse
+// @Failure      401       {object}  ErrorResponse
+// @Failure      403       {object}  ErrorResponse
+// @Failure      404       {object}  ErrorResponse
+// @Failure      409       {object}  ErrorResponse
+// @Failure      412       {object}  ErrorResponse
+// @Failure      428       {object}  ErrorResponse
+// @Router       /api/produk/{id} [delete]
 func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/produk/")
-	id, err := strconv.Atoi(idStr)
+	if !authorize(w, r, models.PermProductDelete) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid product ID")
+		return
+	}
+	version, ok := ifMatch(w, r)
+	if !ok {
 		return
 	}
 
-	err = h.service.Delete(id)
+	err = h.service.Delete(r.Context(), id, version, actorFrom(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 		return
 	}
 
@@ -139,3 +420,39 @@ func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// Restore godoc
+// @Summary      Restore product
+// @Description  Bring back a deleted product. Fails with 409 if it is not deleted, its category is deleted, or another product has since taken its SKU or barcode.
+// @Tags         produk
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int   true  "Product ID"
+// @Success      200  {object}  models.Product
+// @Header       200  {string}  ETag  "Version of the restored product"
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Router       /api/produk/{id}/restore [post]
+func (h *ProductHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, models.PermProductDelete) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid product ID")
+		return
+	}
+
+	product, err := h.service.Restore(r.Context(), id, actorFrom(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	setETag(w, product.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
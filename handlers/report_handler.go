@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"time"
+)
+
+type ReportHandler struct {
+	service *services.ReportService
+}
+
+func NewReportHandler(service *services.ReportService) *ReportHandler {
+	return &ReportHandler{service: service}
+}
+
+// Routes registers the report endpoints on mux.
+func (h *ReportHandler) Routes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/report", h.Range)
+	mux.HandleFunc("GET /api/report/hari-ini", h.Today)
+}
+
+// Today godoc
+// @Summary      Today's sales report
+// @Description  Revenue, transaction count and best seller since local midnight
+// @Tags         report
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.SalesReport
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Router       /api/report/hari-ini [get]
+func (h *ReportHandler) Today(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, models.PermReportRead) {
+		return
+	}
+
+	report, err := h.service.Today(r.Context())
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// Range godoc
+// @Summary      Sales report for a date range
+// @Description  Revenue, transaction count and best seller between two dates, both inclusive
+// @Tags         report
+// @Produce      json
+// @Security     BearerAuth
+// @Param        start_date  query     string  true  "First day, YYYY-MM-DD"
+// @Param        end_date    query     string  true  "Last day, YYYY-MM-DD"
+// @Success      200         {object}  models.SalesReport
+// @Failure      400         {object}  ErrorResponse
+// @Failure      401         {object}  ErrorResponse
+// @Failure      403         {object}  ErrorResponse
+// @Failure      422         {object}  ErrorResponse
+// @Router       /api/report [get]
+func (h *ReportHandler) Range(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, models.PermReportRead) {
+		return
+	}
+
+	start, err := time.ParseInLocation("2006-01-02", r.URL.Query().Get("start_date"), time.Local)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid start_date, expected YYYY-MM-DD")
+		return
+	}
+	end, err := time.ParseInLocation("2006-01-02", r.URL.Query().Get("end_date"), time.Local)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid end_date, expected YYYY-MM-DD")
+		return
+	}
+
+	report, err := h.service.Range(r.Context(), start, end)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
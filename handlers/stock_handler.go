@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+)
+
+type StockHandler struct {
+	service *services.StockService
+}
+
+func NewStockHandler(service *services.StockService) *StockHandler {
+	return &StockHandler{service: service}
+}
+
+// Routes registers the stock ledger endpoints on mux.
+func (h *StockHandler) Routes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/produk/{id}/stock-adjustments", h.Adjust)
+	mux.HandleFunc("GET /api/produk/{id}/stock-history", h.History)
+}
+
+// Adjust godoc
+// @Summary      Adjust stock
+// @Description  Book a restock, return, wastage or correction against a product's stock. Restock, return and wastage take a positive quantity; an adjustment takes a signed change. Adjustments and wastage need a reason.
+// @Tags         stock
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      int                            true  "Product ID"
+// @Param        adjustment  body      models.StockAdjustmentRequest  true  "Stock movement"
+// @Success      201         {object}  models.StockMovement
+// @Failure      400         {object}  ErrorResponse
+// @Failure      401         {object}  ErrorResponse
+// @Failure      403         {object}  ErrorResponse
+// @Failure      404         {object}  ErrorResponse
+// @Failure      409         {object}  ErrorResponse
+// @Failure      422         {object}  ErrorResponse
+// @Router       /api/produk/{id}/stock-adjustments [post]
+func (h *StockHandler) Adjust(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, models.PermStockAdjust) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid product ID")
+		return
+	}
+
+	var req models.StockAdjustmentRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid request body")
+		return
+	}
+
+	movement, err := h.service.Adjust(r.Context(), id, &req, actorFrom(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(movement)
+}
+
+// History godoc
+// @Summary      Stock history
+// @Description  A product's stock movements with the balance after each, newest first unless sorted otherwise
+// @Tags         stock
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id     path       int                   true   "Product ID"
+// @Param        kind   query      string                false  "sale, restock, adjustment, return or wastage"
+// @Param        page   query      int                   false  "Page number, from 1 to 10000"
+// @Param        limit  query      int                   false  "Page size, at most 100"
+// @Param        sort   query      string                false  "Comma-separated id or created_at; prefix - for descending"
+// @Success      200    {array}    models.StockMovement
+// @Header       200    {integer}  X-Total-Count         "Total matching movements"
+// @Failure      400    {object}   ErrorResponse
+// @Failure      401    {object}   ErrorResponse
+// @Failure      403    {object}   ErrorResponse
+// @Failure      404    {object}   ErrorResponse
+// @Failure      422    {object}   ErrorResponse
+// @Router       /api/produk/{id}/stock-history [get]
+func (h *StockHandler) History(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, models.PermProductRead) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid product ID")
+		return
+	}
+
+	q := r.URL.Query()
+	opts, err := parseListOptions(q)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+
+	movements, total, err := h.service.History(r.Context(), id, models.StockMovementFilter{
+		ListOptions: opts,
+		Kind:        q.Get("kind"),
+	})
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	setTotalCount(w, total)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(movements)
+}
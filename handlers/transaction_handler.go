@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+)
+
+type TransactionHandler struct {
+	service *services.TransactionService
+}
+
+func NewTransactionHandler(service *services.TransactionService) *TransactionHandler {
+	return &TransactionHandler{service: service}
+}
+
+// Routes registers the checkout endpoint on mux.
+func (h *TransactionHandler) Routes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/checkout", h.Checkout)
+}
+
+// Checkout godoc
+// @Summary      Checkout
+// @Description  Record a sale and take the sold quantities off stock. Items name a product by product_id or by scanned barcode.
+// @Tags         checkout
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        checkout  body      models.CheckoutRequest  true  "Cart items"
+// @Success      201       {object}  models.Transaction
+// @Failure      400       {object}  ErrorResponse
+// @Failure      401       {object}  ErrorResponse
+// @Failure      403       {object}  ErrorResponse
+// @Failure      404       {object}  ErrorResponse
+// @Failure      409       {object}  ErrorResponse
+// @Failure      422       {object}  ErrorResponse
+// @Router       /api/checkout [post]
+func (h *TransactionHandler) Checkout(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, models.PermCheckout) {
+		return
+	}
+
+	var req models.CheckoutRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid request body")
+		return
+	}
+
+	transaction, err := h.service.Checkout(r.Context(), &req, actorFrom(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(transaction)
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+)
+
+type UserHandler struct {
+	service *services.AuthService
+}
+
+func NewUserHandler(service *services.AuthService) *UserHandler {
+	return &UserHandler{service: service}
+}
+
+// Routes registers the user management endpoints on mux.
+func (h *UserHandler) Routes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/users", h.Create)
+}
+
+// Create godoc
+// @Summary      Create user
+// @Description  Register an account with the cashier, supervisor or admin role
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        user  body      models.CreateUserRequest  true  "Account details"
+// @Success      201   {object}  models.User
+// @Failure      400   {object}  ErrorResponse
+// @Failure      401   {object}  ErrorResponse
+// @Failure      403   {object}  ErrorResponse
+// @Failure      409   {object}  ErrorResponse
+// @Failure      422   {object}  ErrorResponse
+// @Router       /api/users [post]
+func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, models.PermUserManage) {
+		return
+	}
+
+	var req models.CreateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad_request", "invalid request body")
+		return
+	}
+
+	user, err := h.service.CreateUser(r.Context(), &req)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user)
+}
@@ -1,17 +1,27 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"kasir-api/database"
 	"kasir-api/handlers"
+	"kasir-api/logging"
+	"kasir-api/metrics"
+	"kasir-api/models"
+	"kasir-api/notify"
 	"kasir-api/repositories"
 	"kasir-api/services"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
-	"strconv"
+	"os/signal"
+	"runtime"
+	"runtime/debug"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/viper"
 
@@ -22,7 +32,7 @@ import (
 
 // @title           Kasir API
 // @version         1.0
-// @description     API untuk sistem kasir sederhana dengan Category
+// @description     API untuk sistem kasir sederhana: katalog produk dan kategori, checkout, laporan penjualan dan audit log
 // @termsOfService  http://swagger.io/terms/
 
 // @contact.name   API Support
@@ -34,237 +44,396 @@ import (
 // @host      localhost:8080
 // @BasePath  /
 
-// Produk struct
-type Produk struct {
-	ID    int    `json:"id" example:"1"`
-	Nama  string `json:"nama" example:"Indomie Goreng"`
-	Harga int    `json:"harga" example:"3500"`
-	Stok  int    `json:"stok" example:"100"`
-}
-
-var produk = []Produk{
-	{ID: 1, Nama: "Indomie Goreng", Harga: 3500, Stok: 100},
-	{ID: 2, Nama: "Teh Botol", Harga: 3000, Stok: 50},
-	{ID: 3, Nama: "Kecap Bango", Harga: 12000, Stok: 20},
-}
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 Access token from /api/auth/login, sent as "Bearer <token>".
 
+// version and commit identify the build. Release builds set them with
+// -ldflags "-X main.version=v1.2.3 -X main.commit=abc123".
+var (
+	version = "dev"
+	commit  = ""
+)
 
-
-// ==================== PRODUK HANDLERS (existing) ====================
-
-// GetAllProduk godoc
-// @Summary      Get all products
-// @Description  Get list of all products
-// @Tags         produk
-// @Accept       json
-// @Produce      json
-// @Success      200  {array}   Produk
-// @Router       /api/produk [get]
-func getAllProduk(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(produk)
+// publicRoutes are the mux patterns served without an access token. Every
+// other route, including ones added later, requires one.
+var publicRoutes = []string{
+	"POST /api/auth/login",
+	"POST /api/auth/refresh",
+	"POST /api/auth/logout",
+	"GET /api/health",
+	"GET /api/health/live",
+	"GET /api/health/ready",
+	"GET /swagger/",
+	// /metrics checks its own static scrape token, see METRICS_TOKEN.
+	"GET /metrics",
 }
 
-// CreateProduk godoc
-// @Summary      Create new product
-// @Description  Add a new product to inventory
-// @Tags         produk
-// @Accept       json
-// @Produce      json
-// @Param        produk  body      Produk  true  "Product data"
-// @Success      201     {object}  Produk
-// @Failure      400     {object}  map[string]string
-// @Router       /api/produk [post]
-func createProduk(w http.ResponseWriter, r *http.Request) {
-	var produkBaru Produk
-	err := json.NewDecoder(r.Body).Decode(&produkBaru)
-	if err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
-		return
+func main() {
+	// Load environment variable
+	viper.AutomaticEnv()
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+
+	if _, err := os.Stat(".env"); err == nil {
+		viper.SetConfigFile(".env")
+		_ = viper.ReadInConfig()
 	}
 
-	produkBaru.ID = len(produk) + 1
-	produk = append(produk, produkBaru)
+	// Buat Data Model untuk menyimpan config variable
+	type Config struct {
+		Port    string `mapstructure:"PORT"`
+		DBConn  string `mapstructure:"DB_CONN"`
+		Storage string `mapstructure:"STORAGE"`
+		// LogLevel is debug, info, warn or error; LogFormat is json or text.
+		LogLevel  string `mapstructure:"LOG_LEVEL"`
+		LogFormat string `mapstructure:"LOG_FORMAT"`
+		// AutoMigrate applies pending schema migrations at startup.
+		AutoMigrate bool `mapstructure:"DB_AUTO_MIGRATE"`
+		// DBTimeout caps how long one request may spend waiting on the
+		// database before it is answered with 504. Zero disables it.
+		DBTimeout time.Duration `mapstructure:"DB_TIMEOUT"`
+		// HealthTimeout bounds the database probes of the readiness check.
+		HealthTimeout time.Duration `mapstructure:"HEALTH_TIMEOUT"`
+		// JWTSecret is the HMAC key for signing access tokens.
+		JWTSecret     string        `mapstructure:"JWT_SECRET"`
+		JWTAccessTTL  time.Duration `mapstructure:"JWT_ACCESS_TTL"`
+		JWTRefreshTTL time.Duration `mapstructure:"JWT_REFRESH_TTL"`
+		// AdminUsername and AdminPassword, when set, create that account
+		// at startup if it does not exist yet.
+		AdminUsername string `mapstructure:"ADMIN_USERNAME"`
+		AdminPassword string `mapstructure:"ADMIN_PASSWORD"`
+		// MetricsToken is the bearer token Prometheus presents to scrape
+		// /metrics. The endpoint is not served when it is empty.
+		MetricsToken string `mapstructure:"METRICS_TOKEN"`
+		// LowStockNotifier is log, webhook or none. Alerts are sent when a
+		// checkout takes a product below its reorder level; webhook
+		// deliveries POST to LowStockWebhookURL.
+		LowStockNotifier       string        `mapstructure:"LOW_STOCK_NOTIFIER"`
+		LowStockWebhookURL     string        `mapstructure:"LOW_STOCK_WEBHOOK_URL"`
+		LowStockWebhookTimeout time.Duration `mapstructure:"LOW_STOCK_WEBHOOK_TIMEOUT"`
+		// HTTP server timeouts. ShutdownTimeout bounds how long in-flight
+		// requests get to finish after SIGINT or SIGTERM.
+		ReadTimeout       time.Duration `mapstructure:"HTTP_READ_TIMEOUT"`
+		ReadHeaderTimeout time.Duration `mapstructure:"HTTP_READ_HEADER_TIMEOUT"`
+		WriteTimeout      time.Duration `mapstructure:"HTTP_WRITE_TIMEOUT"`
+		IdleTimeout       time.Duration `mapstructure:"HTTP_IDLE_TIMEOUT"`
+		ShutdownTimeout   time.Duration `mapstructure:"HTTP_SHUTDOWN_TIMEOUT"`
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(produkBaru)
-}
+	viper.SetDefault("STORAGE", "postgres")
+	viper.SetDefault("LOG_LEVEL", "info")
+	viper.SetDefault("LOG_FORMAT", "json")
+	viper.SetDefault("DB_TIMEOUT", "10s")
+	viper.SetDefault("HEALTH_TIMEOUT", "2s")
+	viper.SetDefault("JWT_ACCESS_TTL", "15m")
+	viper.SetDefault("JWT_REFRESH_TTL", "168h")
+	viper.SetDefault("LOW_STOCK_NOTIFIER", "log")
+	viper.SetDefault("LOW_STOCK_WEBHOOK_TIMEOUT", "5s")
+	viper.SetDefault("HTTP_READ_TIMEOUT", "15s")
+	viper.SetDefault("HTTP_READ_HEADER_TIMEOUT", "5s")
+	viper.SetDefault("HTTP_WRITE_TIMEOUT", "30s")
+	viper.SetDefault("HTTP_IDLE_TIMEOUT", "60s")
+	viper.SetDefault("HTTP_SHUTDOWN_TIMEOUT", "20s")
 
-// GetProdukByID godoc
-// @Summary      Get product by ID
-// @Description  Get single product by ID
-// @Tags         produk
-// @Accept       json
-// @Produce      json
-// @Param        id   path      int  true  "Product ID"
-// @Success      200  {object}  Produk
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Router       /api/produk/{id} [get]
-func getProdukByID(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/produk/")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
-		return
+	config := Config{
+		Port:          viper.GetString("PORT"),
+		DBConn:        viper.GetString("DB_CONN"),
+		Storage:       viper.GetString("STORAGE"),
+		LogLevel:      viper.GetString("LOG_LEVEL"),
+		LogFormat:     viper.GetString("LOG_FORMAT"),
+		AutoMigrate:   viper.GetBool("DB_AUTO_MIGRATE"),
+		DBTimeout:     viper.GetDuration("DB_TIMEOUT"),
+		HealthTimeout: viper.GetDuration("HEALTH_TIMEOUT"),
+
+		JWTSecret:     viper.GetString("JWT_SECRET"),
+		JWTAccessTTL:  viper.GetDuration("JWT_ACCESS_TTL"),
+		JWTRefreshTTL: viper.GetDuration("JWT_REFRESH_TTL"),
+		AdminUsername: viper.GetString("ADMIN_USERNAME"),
+		AdminPassword: viper.GetString("ADMIN_PASSWORD"),
+		MetricsToken:  viper.GetString("METRICS_TOKEN"),
+
+		LowStockNotifier:       viper.GetString("LOW_STOCK_NOTIFIER"),
+		LowStockWebhookURL:     viper.GetString("LOW_STOCK_WEBHOOK_URL"),
+		LowStockWebhookTimeout: viper.GetDuration("LOW_STOCK_WEBHOOK_TIMEOUT"),
+
+		ReadTimeout:       viper.GetDuration("HTTP_READ_TIMEOUT"),
+		ReadHeaderTimeout: viper.GetDuration("HTTP_READ_HEADER_TIMEOUT"),
+		WriteTimeout:      viper.GetDuration("HTTP_WRITE_TIMEOUT"),
+		IdleTimeout:       viper.GetDuration("HTTP_IDLE_TIMEOUT"),
+		ShutdownTimeout:   viper.GetDuration("HTTP_SHUTDOWN_TIMEOUT"),
 	}
 
-	for _, p := range produk {
-		if p.ID == id {
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(p)
-			return
-		}
+	logger, err := logging.New(os.Stdout, config.LogLevel, config.LogFormat)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
+	slog.SetDefault(logger)
 
-	http.Error(w, "Produk tidak ditemukan", http.StatusNotFound)
-}
-
-// UpdateProduk godoc
-// @Summary      Update product
-// @Description  Update product by ID
-// @Tags         produk
-// @Accept       json
-// @Produce      json
-// @Param        id      path      int     true  "Product ID"
-// @Param        produk  body      Produk  true  "Product data"
-// @Success      200     {object}  Produk
-// @Failure      400     {object}  map[string]string
-// @Failure      404     {object}  map[string]string
-// @Router       /api/produk/{id} [put]
-func updateProduk(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/produk/")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(config.DBConn, os.Args[2:])
 		return
 	}
 
-	var updateData Produk
-	err = json.NewDecoder(r.Body).Decode(&updateData)
-	if err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
-		return
+	if len(config.JWTSecret) < 32 {
+		fatal("JWT_SECRET must be set to at least 32 characters")
 	}
 
-	for i := range produk {
-		if produk[i].ID == id {
-			updateData.ID = id
-			produk[i] = updateData
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(updateData)
-			return
+	var (
+		db              *sql.DB
+		productRepo     repositories.ProductStore
+		categoryRepo    repositories.CategoryStore
+		userRepo        repositories.UserStore
+		stockRepo       repositories.StockStore
+		auditRepo       repositories.AuditStore
+		transactionRepo repositories.TransactionStore
+		reportRepo      repositories.ReportStore
+	)
+
+	switch config.Storage {
+	case "memory":
+		// Demo mode: nothing persists across restarts.
+		store := repositories.NewMemoryStore()
+		store.SeedDemo()
+		productRepo = store.Products()
+		categoryRepo = store.Categories()
+		userRepo = store.Users()
+		stockRepo = store.Stock()
+		auditRepo = store.Audit()
+		transactionRepo = store.Transactions()
+		reportRepo = store.Reports()
+		slog.Info("using in-memory storage; data is lost on restart")
+	case "postgres":
+		if config.DBConn == "" {
+			fatal("DB_CONN environment variable is not set")
 		}
-	}
+		db, err = database.InitDB(config.DBConn)
+		if err != nil {
+			fatal("failed to initialize database", "err", err)
+		}
+		metrics.RegisterDB(db, "kasir")
 
-	http.Error(w, "Produk tidak ditemukan", http.StatusNotFound)
-}
+		if config.AutoMigrate {
+			if _, err := database.MigrateUp(db); err != nil {
+				fatal("failed to migrate database", "err", err)
+			}
+		}
+
+		pgProductRepo := repositories.NewProductRepository(db)
+		productRepo = pgProductRepo
+		categoryRepo = repositories.NewCategoryRepository(db)
+		userRepo = repositories.NewUserRepository(db)
+		stockRepo = repositories.NewStockRepository(db)
+		auditRepo = repositories.NewAuditRepository(db)
+
+		transactionRepo = repositories.NewTransactionRepository(db, pgProductRepo)
+		reportRepo = repositories.NewReportRepository(db)
+	default:
+		fatal("unknown STORAGE, expected postgres or memory", "storage", config.Storage)
+	}
 
-// DeleteProduk godoc
-// @Summary      Delete product
-// @Description  Delete product by ID
-// @Tags         produk
-// @Accept       json
-// @Produce      json
-// @Param        id   path      int  true  "Product ID"
-// @Success      200  {object}  map[string]string
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
-// @Router       /api/produk/{id} [delete]
-func deleteProduk(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/produk/")
-	id, err := strconv.Atoi(idStr)
+	// Only checkouts raise low-stock alerts, so the checker runs alongside
+	// them.
+	notifier, err := notify.New(config.LowStockNotifier, config.LowStockWebhookURL, config.LowStockWebhookTimeout)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
-		return
+		fatal("invalid LOW_STOCK_NOTIFIER configuration", "err", err)
+	}
+	var lowStock *services.LowStockChecker
+	if notifier != nil {
+		lowStock = services.NewLowStockChecker(notifier, config.LowStockWebhookTimeout)
+		lowStock.Start()
 	}
 
-	for i, p := range produk {
-		if p.ID == id {
-			produk = append(produk[:i], produk[i+1:]...)
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(map[string]string{
-				"message": "Produk berhasil dihapus",
-			})
-			return
+	authService := services.NewAuthService(userRepo, services.AuthConfig{
+		Secret:     []byte(config.JWTSecret),
+		AccessTTL:  config.JWTAccessTTL,
+		RefreshTTL: config.JWTRefreshTTL,
+	})
+	authHandler := handlers.NewAuthHandler(authService)
+	if config.AdminUsername != "" && config.AdminPassword != "" {
+		if err := authService.EnsureAdmin(context.Background(), config.AdminUsername, config.AdminPassword); err != nil {
+			fatal("failed to create admin user", "err", err)
 		}
 	}
 
-	http.Error(w, "Produk tidak ditemukan", http.StatusNotFound)
-}
-
-// HealthCheck godoc
-// @Summary      Health check
-// @Description  Check if API is running
-// @Tags         health
-// @Accept       json
-// @Produce      json
-// @Success      200  {object}  map[string]string
-// @Router       /api/health [get]
-func healthCheck(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"status":  "OK",
-		"message": "API Running",
-	})
-}
+	productService := services.NewProductService(productRepo)
+	productHandler := handlers.NewProductHandler(productService)
 
-func main() {
-	// Load environment variable
-	viper.AutomaticEnv()
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	categoryService := services.NewCategoryService(categoryRepo, productRepo)
+	categoryHandler := handlers.NewCategoryHandler(categoryService)
 
-	if _, err := os.Stat(".env"); err == nil {
-		viper.SetConfigFile(".env")
-		_ = viper.ReadInConfig()
+	stockService := services.NewStockService(stockRepo)
+	stockHandler := handlers.NewStockHandler(stockService)
+
+	transactionService := services.NewTransactionService(transactionRepo, productRepo, lowStock)
+	transactionHandler := handlers.NewTransactionHandler(transactionService)
+
+	reportService := services.NewReportService(reportRepo)
+	reportHandler := handlers.NewReportHandler(reportService)
+
+	auditService := services.NewAuditService(auditRepo)
+	auditHandler := handlers.NewAuditHandler(auditService)
+
+	userHandler := handlers.NewUserHandler(authService)
+
+	healthService := services.NewHealthService(db, buildInfo(), config.HealthTimeout)
+	healthHandler := handlers.NewHealthHandler(healthService)
+
+	// Setup routes. Only publicRoutes are reachable without a token, and
+	// handlers check the caller's permissions themselves. /metrics carries
+	// revenue, so it is only served behind the static METRICS_TOKEN.
+	mux := http.NewServeMux()
+	authHandler.Routes(mux)
+	userHandler.Routes(mux)
+	productHandler.Routes(mux)
+	categoryHandler.Routes(mux)
+	stockHandler.Routes(mux)
+	auditHandler.Routes(mux)
+	transactionHandler.Routes(mux)
+	reportHandler.Routes(mux)
+	healthHandler.Routes(mux)
+	if config.MetricsToken != "" {
+		mux.Handle("GET /metrics", handlers.RequireToken(config.MetricsToken, metrics.Handler()))
+	} else {
+		slog.Warn("METRICS_TOKEN is not set, /metrics is disabled")
 	}
-
-	// Buat Data Model untuk menyimpan config variable
-	type Config struct {
-		Port    string `mapstructure:"PORT"`
-		DBConn string `mapstructure:"DB_CONN"`
+	// Swagger UI
+	mux.HandleFunc("GET /swagger/", httpSwagger.WrapHandler)
+
+	slog.Info("server running", "addr", "http://localhost:"+config.Port,
+		"swagger", "http://localhost:"+config.Port+"/swagger/index.html")
+
+	// Middleware, innermost first.
+	var handler http.Handler = handlers.JSONFallback(mux)
+	handler = handlers.Authenticate(authService, mux, publicRoutes...)(handler)
+	handler = handlers.Metrics(mux)(handler)
+	handler = handlers.Timeout(config.DBTimeout)(handler)
+	handler = handlers.AccessLog(logger)(handler)
+	handler = handlers.RequestID(handler)
+
+	server := &http.Server{
+		Addr:              ":" + config.Port,
+		Handler:           handler,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+	}
+	serveErr := runServer(server, config.ShutdownTimeout)
+
+	// Handlers cut off by a timed-out shutdown may still be running; the
+	// checker drops whatever alerts they queue from here on.
+	if lowStock != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+		lowStock.Stop(ctx)
+		cancel()
+	}
+	if db != nil {
+		db.Close()
 	}
+	if serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+		fatal("server failed", "err", serveErr)
+	}
+}
 
-	config := Config{
-		Port:   viper.GetString("PORT"),
-		DBConn: viper.GetString("DB_CONN"),
+// runServer serves until SIGINT or SIGTERM, then stops accepting connections
+// and waits up to shutdownTimeout for in-flight requests, such as a
+// checkout's transaction, to complete.
+func runServer(server *http.Server, shutdownTimeout time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
 	}
+	// A second signal kills the process straight away.
+	stop()
+
+	slog.Info("shutting down, waiting for in-flight requests", "timeout", shutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("graceful shutdown timed out, closing remaining connections", "err", err)
+		return server.Close()
+	}
+	slog.Info("server stopped")
+	return nil
+}
 
-	//Setup database
-	db, err := database.InitDB(config.DBConn)
-	fmt.Println("DB Connection String:", config.DBConn)
-	if config.DBConn == "" {
-		log.Fatal("DB_CONN environment variable is not set")
+// runMigrate implements the "kasir-api migrate up|down|status" subcommand.
+func runMigrate(dbConn string, args []string) {
+	if len(args) != 1 {
+		fatal("usage: kasir-api migrate up|down|status")
 	}
+	if dbConn == "" {
+		fatal("DB_CONN environment variable is not set")
+	}
+
+	db, err := database.InitDB(dbConn)
 	if err != nil {
-		log.Fatal("Failed to initialize database:", err)
+		fatal("failed to initialize database", "err", err)
 	}
 	defer db.Close()
 
+	switch args[0] {
+	case "up":
+		n, err := database.MigrateUp(db)
+		if err != nil {
+			fatal("migration failed", "err", err)
+		}
+		fmt.Printf("Applied %d migration(s)\n", n)
+	case "down":
+		ok, err := database.MigrateDown(db)
+		if err != nil {
+			fatal("migration failed", "err", err)
+		}
+		if !ok {
+			fmt.Println("No migrations to roll back")
+		}
+	case "status":
+		statuses, err := database.Status(db)
+		if err != nil {
+			fatal("migration failed", "err", err)
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, applied)
+		}
+	default:
+		fatal("unknown migrate command, expected up, down or status", "command", args[0])
+	}
+}
 
-	productRepo := repositories.NewProductRepository(db)
-	productService := services.NewProductService(productRepo)
-	productHandler := handlers.NewProductHandler(productService)
-
-	categoryRepo := repositories.NewCategoryRepository(db)
-	categoryService := services.NewCategoryService(categoryRepo)
-	categoryHandler := handlers.NewCategoryHandler(categoryService)
-
-	// Setup routes
-	http.HandleFunc("/api/produk", productHandler.HandleProducts)
-	http.HandleFunc("/api/produk/", productHandler.HandleProductByID)
-	http.HandleFunc("/api/categories", categoryHandler.HandleCategories)
-	http.HandleFunc("/api/categories/", categoryHandler.HandleCategoryByID)
-	http.HandleFunc("/api/health", healthCheck)
-	// Swagger UI
-	http.HandleFunc("/swagger/", httpSwagger.WrapHandler)
-
-	fmt.Println("Server running di http://localhost:" + config.Port)
-	fmt.Println("Swagger UI: http://localhost:" + config.Port + "/swagger/index.html")
-
-	err = http.ListenAndServe(":"+ config.Port, nil)
-	if err != nil {
-		fmt.Println("Error starting server:", err)
+// buildInfo reports the version and commit set at link time, falling back
+// to the VCS revision the Go toolchain stamps into the binary.
+func buildInfo() models.BuildInfo {
+	info := models.BuildInfo{Version: version, Commit: commit, GoVersion: runtime.Version()}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range bi.Settings {
+				if setting.Key == "vcs.revision" {
+					info.Commit = setting.Value
+				}
+			}
+		}
 	}
+	return info
+}
+
+// fatal logs msg at error level and exits.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
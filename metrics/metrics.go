@@ -0,0 +1,75 @@
+// Package metrics defines the Prometheus collectors exposed on /metrics.
+// They live on the default registry, next to the Go runtime and process
+// collectors it already carries.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "kasir"
+
+var (
+	// HTTPRequests counts finished requests by method, route pattern and
+	// status code.
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled, by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration observes request latency by method, route pattern and
+	// status code.
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency, by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// Checkouts counts completed sales.
+	Checkouts = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "checkouts_total",
+		Help:      "Completed checkouts.",
+	})
+
+	// ItemsSold counts units sold across all checkouts.
+	ItemsSold = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "items_sold_total",
+		Help:      "Product units sold.",
+	})
+
+	// Revenue sums the total amount of completed checkouts.
+	Revenue = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "revenue_rupiah_total",
+		Help:      "Revenue from completed checkouts, in rupiah.",
+	})
+
+	// LowStockAlerts counts low-stock alerts by outcome: sent, failed, or
+	// dropped because the alert queue was full.
+	LowStockAlerts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "low_stock_alerts_total",
+		Help:      "Low-stock alerts, by outcome.",
+	}, []string{"result"})
+)
+
+// RegisterDB exports the connection pool statistics of db (open, in use,
+// idle, wait count and wait time) as go_sql_* metrics.
+func RegisterDB(db *sql.DB, name string) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the default registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
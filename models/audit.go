@@ -0,0 +1,59 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Actor identifies who made a change, for the audit log.
+type Actor struct {
+	ID       int
+	Username string
+}
+
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+
+	AuditEntityProduct  = "product"
+	AuditEntityCategory = "category"
+)
+
+// AuditEntry records one catalogue change. Before is null for creations
+// and After is null for deletions.
+type AuditEntry struct {
+	ID        int64           `json:"id"`
+	ActorID   *int            `json:"actor_id"`
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	EntityID  int             `json:"entity_id"`
+	Before    json.RawMessage `json:"before" swaggertype:"object"`
+	After     json.RawMessage `json:"after" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+var AuditSortFields = []string{"id", "created_at"}
+
+type AuditFilter struct {
+	ListOptions
+	Entity   string
+	EntityID *int
+}
+
+func (f *AuditFilter) Validate() map[string]string {
+	errs := make(map[string]string)
+	f.validate(errs, AuditSortFields)
+	if f.Entity != "" && f.Entity != AuditEntityProduct && f.Entity != AuditEntityCategory {
+		errs["entity"] = "must be product or category"
+	}
+	if f.EntityID != nil && f.Entity == "" {
+		errs["id"] = "requires entity"
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
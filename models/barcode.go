@@ -0,0 +1,34 @@
+package models
+
+// NormalizeBarcode returns the EAN-13 form of a UPC-A or EAN-13 code and
+// whether the code is well-formed with a correct check digit. UPC-A codes
+// are EAN-13 codes with a leading zero, so storing the 13-digit form lets
+// either scan find the same product.
+func NormalizeBarcode(code string) (string, bool) {
+	if len(code) == 12 {
+		code = "0" + code
+	}
+	if len(code) != 13 {
+		return "", false
+	}
+	sum := 0
+	for i := 0; i < 13; i++ {
+		c := code[i]
+		if c < '0' || c > '9' {
+			return "", false
+		}
+		d := int(c - '0')
+		if i == 12 {
+			if (10-sum%10)%10 != d {
+				return "", false
+			}
+			break
+		}
+		// EAN-13 weights alternate 1 and 3 from the left.
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return code, true
+}
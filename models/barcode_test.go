@@ -0,0 +1,30 @@
+package models
+
+import "testing"
+
+func TestNormalizeBarcode(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+		ok   bool
+	}{
+		{"ean-13", "8886008101053", "8886008101053", true},
+		{"ean-13 with leading zero", "0089686010947", "0089686010947", true},
+		{"upc-a gains a leading zero", "089686010947", "0089686010947", true},
+		{"wrong check digit", "8886008101054", "", false},
+		{"wrong upc-a check digit", "089686010948", "", false},
+		{"non-digit", "88860081010a3", "", false},
+		{"too short", "12345", "", false},
+		{"too long", "88860081010530", "", false},
+		{"empty", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NormalizeBarcode(tt.code)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("NormalizeBarcode(%q) = %q, %v; want %q, %v", tt.code, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
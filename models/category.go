@@ -1,7 +1,47 @@
 package models
 
+import (
+	"strings"
+	"time"
+)
+
 type Category struct {
 	ID          int    `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	// Version is bumped by every change and sent as the ETag.
+	Version int `json:"version"`
+	// DeletedAt is set once the category is deleted.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// CategoryPatchFields are the JSON fields a merge patch may change;
+// CategoryReadOnlyFields are maintained by the server.
+var (
+	CategoryPatchFields    = []string{"name", "description"}
+	CategoryReadOnlyFields = []string{"id", "version", "deleted_at"}
+)
+
+// ClearReadOnly drops the server-maintained fields a client may have sent
+// in a request body. ID and Version come from the path and If-Match.
+func (c *Category) ClearReadOnly() {
+	c.DeletedAt = nil
+}
+
+// Validate checks the category against its field rules and returns a
+// message per offending JSON field, or nil when it is valid.
+func (c *Category) Validate() map[string]string {
+	errs := make(map[string]string)
+	if strings.TrimSpace(c.Name) == "" {
+		errs["name"] = "is required"
+	} else if len(c.Name) > 100 {
+		errs["name"] = "must be at most 100 characters"
+	}
+	if len(c.Description) > 1000 {
+		errs["description"] = "must be at most 1000 characters"
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
 }
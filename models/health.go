@@ -0,0 +1,41 @@
+package models
+
+// Health statuses. A report is HealthOK only when every check is.
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+	HealthFail     = "fail"
+)
+
+// BuildInfo identifies the running binary.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	GoVersion string `json:"go_version"`
+}
+
+// HealthCheck is the outcome of one readiness probe.
+type HealthCheck struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms,omitempty"`
+	Message   string  `json:"message,omitempty"`
+}
+
+// PoolStats is a snapshot of the database connection pool.
+type PoolStats struct {
+	MaxOpen      int    `json:"max_open"`
+	Open         int    `json:"open"`
+	InUse        int    `json:"in_use"`
+	Idle         int    `json:"idle"`
+	WaitCount    int64  `json:"wait_count"`
+	WaitDuration string `json:"wait_duration"`
+}
+
+// HealthReport is the body of the liveness and readiness endpoints.
+type HealthReport struct {
+	Status        string                 `json:"status"`
+	Build         BuildInfo              `json:"build"`
+	UptimeSeconds int64                  `json:"uptime_seconds"`
+	Checks        map[string]HealthCheck `json:"checks,omitempty"`
+	Pool          *PoolStats             `json:"pool,omitempty"`
+}
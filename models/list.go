@@ -0,0 +1,100 @@
+package models
+
+import "fmt"
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+	// MaxPage bounds ?page= so the offset stays small enough for the
+	// database to skip and can never overflow.
+	MaxPage = 10000
+)
+
+// SortField is one key of a ?sort= list, e.g. "-price" is {"price", true}.
+type SortField struct {
+	Name string
+	Desc bool
+}
+
+// ListOptions carries paging and ordering for list endpoints. Page is
+// 1-based.
+type ListOptions struct {
+	Page  int
+	Limit int
+	Sort  []SortField
+}
+
+func (o ListOptions) Offset() int {
+	return (o.Page - 1) * o.Limit
+}
+
+// validate records paging and sort problems in errs. Only names listed in
+// sortable may be sorted on.
+func (o ListOptions) validate(errs map[string]string, sortable []string) {
+	if o.Page < 1 || o.Page > MaxPage {
+		errs["page"] = fmt.Sprintf("must be between 1 and %d", MaxPage)
+	}
+	if o.Limit < 1 || o.Limit > MaxPageLimit {
+		errs["limit"] = fmt.Sprintf("must be between 1 and %d", MaxPageLimit)
+	}
+	for _, f := range o.Sort {
+		if !contains(sortable, f.Name) {
+			errs["sort"] = fmt.Sprintf("cannot sort by %q, allowed: %v", f.Name, sortable)
+			break
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+var CategorySortFields = []string{"id", "name"}
+
+type CategoryFilter struct {
+	ListOptions
+	IncludeDeleted bool
+}
+
+func (f *CategoryFilter) Validate() map[string]string {
+	errs := make(map[string]string)
+	f.validate(errs, CategorySortFields)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+var ProductSortFields = []string{"id", "name", "price", "stock", "category_id"}
+
+// ProductFilter narrows a product listing. Nil pointers mean "no filter".
+type ProductFilter struct {
+	ListOptions
+	// Name matches products whose name contains it, case-insensitively.
+	Name       string
+	MinPrice   *int
+	MaxPrice   *int
+	InStock    *bool
+	CategoryID *int
+	// LowStock keeps only products below their reorder level.
+	LowStock bool
+	// IncludeDeleted lists soft-deleted products alongside live ones.
+	IncludeDeleted bool
+}
+
+func (f *ProductFilter) Validate() map[string]string {
+	errs := make(map[string]string)
+	f.validate(errs, ProductSortFields)
+	if f.MinPrice != nil && f.MaxPrice != nil && *f.MinPrice > *f.MaxPrice {
+		errs["min_price"] = "must be <= max_price"
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
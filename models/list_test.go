@@ -0,0 +1,34 @@
+package models
+
+import "testing"
+
+func TestListOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   ListOptions
+		errors []string
+	}{
+		{"defaults", ListOptions{Page: 1, Limit: DefaultPageLimit}, nil},
+		{"last page", ListOptions{Page: MaxPage, Limit: MaxPageLimit}, nil},
+		{"page zero", ListOptions{Page: 0, Limit: 10}, []string{"page"}},
+		{"page past the maximum", ListOptions{Page: MaxPage + 1, Limit: 10}, []string{"page"}},
+		{"page whose offset overflows", ListOptions{Page: 922337203685477582, Limit: 10}, []string{"page"}},
+		{"limit zero", ListOptions{Page: 1, Limit: 0}, []string{"limit"}},
+		{"limit too large", ListOptions{Page: 1, Limit: MaxPageLimit + 1}, []string{"limit"}},
+		{"unknown sort field", ListOptions{Page: 1, Limit: 10, Sort: []SortField{{Name: "secret"}}}, []string{"sort"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := make(map[string]string)
+			tt.opts.validate(errs, CategorySortFields)
+			if len(errs) != len(tt.errors) {
+				t.Fatalf("validate() = %v, want errors for %v", errs, tt.errors)
+			}
+			for _, field := range tt.errors {
+				if _, ok := errs[field]; !ok {
+					t.Errorf("validate() = %v, want an error for %s", errs, field)
+				}
+			}
+		})
+	}
+}
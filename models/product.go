@@ -1,8 +1,112 @@
 package models
 
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+var skuPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
 type Product struct {
-	ID int `json:"id"`
-	Name string `json:"name"`
-	Price int `json:"price"`
-	Stock int `json:"stock"`
-}
\ No newline at end of file
+	ID           int    `json:"id" example:"1"`
+	Name         string `json:"name" example:"Indomie Goreng"`
+	Price        int    `json:"price" example:"3500"`
+	Stock        int    `json:"stock" example:"100"`
+	SKU          string `json:"sku" example:"MKN-001"`
+	Barcode      string `json:"barcode" example:"0089686010947"`
+	CategoryID   *int   `json:"category_id" example:"1"`
+	CategoryName string `json:"category_name,omitempty" example:"Makanan"`
+	// ReorderLevel is the stock below which the product counts as low;
+	// 0 disables low-stock alerts. ReorderQty is the suggested order size.
+	ReorderLevel int `json:"reorder_level" example:"20"`
+	ReorderQty   int `json:"reorder_qty" example:"48"`
+	// Version is bumped by every change, stock movements included, and is
+	// sent as the ETag. It is read-only; updates carry it in If-Match.
+	Version int `json:"version" example:"1"`
+	// DeletedAt is set once the product is deleted; deleted products are
+	// only listed with include_deleted=true.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// ProductPatchFields are the JSON fields a merge patch may change, and
+// ProductNullableFields those of them it may clear with null;
+// ProductReadOnlyFields are maintained by the server.
+var (
+	ProductPatchFields    = []string{"name", "price", "stock", "sku", "barcode", "category_id", "reorder_level", "reorder_qty"}
+	ProductNullableFields = []string{"sku", "barcode", "category_id"}
+	ProductReadOnlyFields = []string{"id", "category_name", "version", "deleted_at"}
+)
+
+// LowStock reports whether stock has fallen below the reorder level.
+func (p *Product) LowStock() bool {
+	return p.Stock < p.ReorderLevel
+}
+
+// ClearReadOnly drops the server-maintained fields a client may have sent
+// in a request body. ID and Version come from the path and If-Match.
+func (p *Product) ClearReadOnly() {
+	p.CategoryName = ""
+	p.DeletedAt = nil
+}
+
+// Validate checks the product against its field rules and returns a
+// message per offending JSON field, or nil when it is valid. A valid
+// barcode is rewritten to its EAN-13 form.
+func (p *Product) Validate() map[string]string {
+	errs := make(map[string]string)
+	if strings.TrimSpace(p.Name) == "" {
+		errs["name"] = "is required"
+	} else if len(p.Name) > 255 {
+		errs["name"] = "must be at most 255 characters"
+	}
+	if p.Price < 0 {
+		errs["price"] = "must be >= 0"
+	}
+	if p.Stock < 0 {
+		errs["stock"] = "must be >= 0"
+	}
+	if p.ReorderLevel < 0 {
+		errs["reorder_level"] = "must be >= 0"
+	}
+	if p.ReorderQty < 0 {
+		errs["reorder_qty"] = "must be >= 0"
+	}
+	if p.SKU != "" && !skuPattern.MatchString(p.SKU) {
+		errs["sku"] = "must be 1-64 letters, digits, '.', '_' or '-'"
+	}
+	if p.Barcode != "" {
+		if code, ok := NormalizeBarcode(p.Barcode); ok {
+			p.Barcode = code
+		} else {
+			errs["barcode"] = "must be a valid EAN-13 or UPC-A code"
+		}
+	}
+	if p.CategoryID != nil && *p.CategoryID <= 0 {
+		errs["category_id"] = "must be a positive ID"
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// ProductSearchResult is a product matched by name search, with a relevance
+// score in [0, 1] where higher is a closer match.
+type ProductSearchResult struct {
+	Product
+	Score float64 `json:"score"`
+}
+
+// LowStockAlert is emitted when a checkout takes a product from at or
+// above its reorder level to below it.
+type LowStockAlert struct {
+	ProductID     int       `json:"product_id"`
+	ProductName   string    `json:"product_name"`
+	SKU           string    `json:"sku"`
+	Stock         int       `json:"stock"`
+	ReorderLevel  int       `json:"reorder_level"`
+	ReorderQty    int       `json:"reorder_qty"`
+	TransactionID int       `json:"transaction_id"`
+	At            time.Time `json:"at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+type BestSeller struct {
+	ProductID    int    `json:"product_id"`
+	Name         string `json:"name"`
+	QuantitySold int    `json:"quantity_sold"`
+}
+
+type SalesReport struct {
+	StartDate         string      `json:"start_date"`
+	EndDate           string      `json:"end_date"`
+	TotalRevenue      int         `json:"total_revenue"`
+	TotalTransactions int         `json:"total_transactions"`
+	BestSeller        *BestSeller `json:"best_seller"`
+}
@@ -0,0 +1,57 @@
+package models
+
+// Role is a user's job at the till; it decides which permissions they hold.
+type Role string
+
+const (
+	RoleCashier    Role = "cashier"
+	RoleSupervisor Role = "supervisor"
+	RoleAdmin      Role = "admin"
+)
+
+// Permission names one guarded action, in "resource:action" form.
+type Permission string
+
+const (
+	PermProductRead    Permission = "product:read"
+	PermProductWrite   Permission = "product:write"
+	PermProductDelete  Permission = "product:delete"
+	PermCategoryRead   Permission = "category:read"
+	PermCategoryWrite  Permission = "category:write"
+	PermCategoryDelete Permission = "category:delete"
+	PermStockAdjust    Permission = "stock:adjust"
+	PermCheckout       Permission = "checkout:create"
+	PermReportRead     Permission = "report:read"
+	PermAuditRead      Permission = "audit:read"
+	PermUserManage     Permission = "user:manage"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleCashier: {
+		PermProductRead, PermCategoryRead, PermCheckout,
+	},
+	RoleSupervisor: {
+		PermProductRead, PermProductWrite, PermCategoryRead, PermCategoryWrite,
+		PermStockAdjust, PermCheckout, PermReportRead, PermAuditRead,
+	},
+	RoleAdmin: {
+		PermProductRead, PermProductWrite, PermProductDelete,
+		PermCategoryRead, PermCategoryWrite, PermCategoryDelete,
+		PermStockAdjust, PermCheckout, PermReportRead, PermAuditRead, PermUserManage,
+	},
+}
+
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Can reports whether the role grants p. Unknown roles grant nothing.
+func (r Role) Can(p Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
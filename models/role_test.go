@@ -0,0 +1,32 @@
+package models
+
+import "testing"
+
+func TestRoleCan(t *testing.T) {
+	tests := []struct {
+		role Role
+		perm Permission
+		want bool
+	}{
+		{RoleCashier, PermCheckout, true},
+		{RoleCashier, PermProductRead, true},
+		{RoleCashier, PermProductWrite, false},
+		{RoleCashier, PermStockAdjust, false},
+		{RoleCashier, PermReportRead, false},
+		{RoleSupervisor, PermProductWrite, true},
+		{RoleSupervisor, PermReportRead, true},
+		{RoleSupervisor, PermAuditRead, true},
+		{RoleSupervisor, PermProductDelete, false},
+		{RoleSupervisor, PermUserManage, false},
+		{RoleAdmin, PermProductDelete, true},
+		{RoleAdmin, PermCategoryDelete, true},
+		{RoleAdmin, PermUserManage, true},
+		{Role("owner"), PermProductRead, false},
+		{Role(""), PermCheckout, false},
+	}
+	for _, tt := range tests {
+		if got := tt.role.Can(tt.perm); got != tt.want {
+			t.Errorf("%q.Can(%s) = %v, want %v", tt.role, tt.perm, got, tt.want)
+		}
+	}
+}
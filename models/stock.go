@@ -0,0 +1,96 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Stock movement kinds. Sales are written by checkout; the others are
+// recorded through stock adjustments.
+const (
+	StockSale       = "sale"
+	StockRestock    = "restock"
+	StockAdjustment = "adjustment"
+	StockReturn     = "return"
+	StockWastage    = "wastage"
+)
+
+// StockMovement is one entry of a product's stock ledger. Quantity is the
+// signed change and Balance the stock left after it.
+type StockMovement struct {
+	ID            int64     `json:"id"`
+	ProductID     int       `json:"product_id"`
+	Kind          string    `json:"kind"`
+	Quantity      int       `json:"quantity"`
+	Balance       int       `json:"balance"`
+	Reason        string    `json:"reason"`
+	TransactionID *int      `json:"transaction_id"`
+	ActorID       *int      `json:"actor_id"`
+	Actor         string    `json:"actor"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// StockAdjustmentRequest is the body of POST /api/produk/{id}/stock-adjustments.
+// For restock, return and wastage Quantity is the positive number of units
+// and the kind gives the direction; an adjustment takes a signed change.
+type StockAdjustmentRequest struct {
+	Kind     string `json:"kind" example:"restock"`
+	Quantity int    `json:"quantity" example:"24"`
+	Reason   string `json:"reason" example:"delivery from supplier"`
+}
+
+// Validate checks the request and returns a message per offending field.
+func (r *StockAdjustmentRequest) Validate() map[string]string {
+	errs := make(map[string]string)
+	switch r.Kind {
+	case StockRestock, StockReturn, StockWastage:
+		if r.Quantity <= 0 {
+			errs["quantity"] = "must be > 0"
+		}
+	case StockAdjustment:
+		if r.Quantity == 0 {
+			errs["quantity"] = "must not be 0"
+		}
+	default:
+		errs["kind"] = "must be restock, adjustment, return or wastage"
+	}
+	if (r.Kind == StockAdjustment || r.Kind == StockWastage) && r.Reason == "" {
+		errs["reason"] = fmt.Sprintf("is required for %s", r.Kind)
+	}
+	if len(r.Reason) > 500 {
+		errs["reason"] = "must be at most 500 characters"
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Delta is the signed stock change the request asks for.
+func (r *StockAdjustmentRequest) Delta() int {
+	if r.Kind == StockWastage {
+		return -r.Quantity
+	}
+	return r.Quantity
+}
+
+var StockMovementSortFields = []string{"id", "created_at"}
+
+type StockMovementFilter struct {
+	ListOptions
+	Kind string
+}
+
+func (f *StockMovementFilter) Validate() map[string]string {
+	errs := make(map[string]string)
+	f.validate(errs, StockMovementSortFields)
+	switch f.Kind {
+	case "", StockSale, StockRestock, StockAdjustment, StockReturn, StockWastage:
+	default:
+		errs["kind"] = "must be sale, restock, adjustment, return or wastage"
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+type Transaction struct {
+	ID          int                 `json:"id"`
+	TotalAmount int                 `json:"total_amount"`
+	CreatedAt   time.Time           `json:"created_at"`
+	Details     []TransactionDetail `json:"details"`
+}
+
+type TransactionDetail struct {
+	ID            int    `json:"id"`
+	TransactionID int    `json:"transaction_id"`
+	ProductID     int    `json:"product_id"`
+	ProductName   string `json:"product_name"`
+	Quantity      int    `json:"quantity"`
+	Price         int    `json:"price"`
+	Subtotal      int    `json:"subtotal"`
+}
+
+// CheckoutItem identifies a product either by ID or by scanned barcode.
+type CheckoutItem struct {
+	ProductID int    `json:"product_id,omitempty"`
+	Barcode   string `json:"barcode,omitempty"`
+	Quantity  int    `json:"quantity"`
+}
+
+type CheckoutRequest struct {
+	Items []CheckoutItem `json:"items"`
+}
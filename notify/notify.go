@@ -0,0 +1,97 @@
+// Package notify delivers low-stock alerts to whoever reorders stock. The
+// Notifier interface lets deployments pick a channel: the log, a webhook,
+// or anything else that implements it.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"kasir-api/models"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Notifier sends one low-stock alert.
+type Notifier interface {
+	Notify(ctx context.Context, alert models.LowStockAlert) error
+}
+
+// New returns the notifier named by kind: log, webhook or none. none
+// returns a nil Notifier, which turns alerts off.
+func New(kind, webhookURL string, timeout time.Duration) (Notifier, error) {
+	switch strings.ToLower(kind) {
+	case "log":
+		return NewLogNotifier(slog.Default()), nil
+	case "webhook":
+		u, err := url.Parse(webhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid webhook URL %q, expected an http or https URL", webhookURL)
+		}
+		return NewWebhookNotifier(webhookURL, timeout), nil
+	case "none":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("invalid notifier %q, expected log, webhook or none", kind)
+	}
+}
+
+// LogNotifier writes alerts to a logger at warn level.
+type LogNotifier struct {
+	logger *slog.Logger
+}
+
+func NewLogNotifier(logger *slog.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, alert models.LowStockAlert) error {
+	n.logger.WarnContext(ctx, "low stock", "product_id", alert.ProductID, "product", alert.ProductName,
+		"sku", alert.SKU, "stock", alert.Stock, "reorder_level", alert.ReorderLevel,
+		"reorder_qty", alert.ReorderQty, "transaction_id", alert.TransactionID)
+	return nil
+}
+
+// WebhookNotifier POSTs each alert as JSON to a URL. Any status outside
+// 2xx counts as a failed delivery.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// webhookPayload wraps the alert so receivers can tell event types apart.
+type webhookPayload struct {
+	Event string               `json:"event"`
+	Alert models.LowStockAlert `json:"alert"`
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, alert models.LowStockAlert) error {
+	body, err := json.Marshal(webhookPayload{Event: "low_stock", Alert: alert})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "kasir-api")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("low stock webhook: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("low stock webhook: unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"kasir-api/models"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// writeAudit records a change inside the caller's transaction, so the log
+// entry commits or rolls back together with the change it describes.
+func writeAudit(ctx context.Context, tx *sql.Tx, actor models.Actor, action, entity string, entityID int, before, after any) error {
+	beforeJSON, err := auditJSON(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := auditJSON(after)
+	if err != nil {
+		return err
+	}
+
+	var actorID *int
+	if actor.ID != 0 {
+		actorID = &actor.ID
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO audit_log (actor_id, actor, action, entity, entity_id, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		actorID, actor.Username, action, entity, entityID, beforeJSON, afterJSON)
+	if err != nil {
+		return dbError("write audit log", err)
+	}
+	return nil
+}
+
+// auditJSON marshals an entity snapshot, keeping nil as SQL NULL.
+func auditJSON(v any) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+var auditSortColumns = map[string]string{
+	"id":         "id",
+	"created_at": "created_at",
+}
+
+// List returns one page of audit entries, newest first unless f.Sort says
+// otherwise, along with the total count.
+func (r *AuditRepository) List(ctx context.Context, f models.AuditFilter) ([]models.AuditEntry, int, error) {
+	var where whereClause
+	if f.Entity != "" {
+		where.add("entity = $%d", f.Entity)
+	}
+	if f.EntityID != nil {
+		where.add("entity_id = $%d", *f.EntityID)
+	}
+
+	sort := f.Sort
+	if len(sort) == 0 {
+		sort = []models.SortField{{Name: "id", Desc: true}}
+	}
+	order, err := orderBy(sort, auditSortColumns, "id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_log"+where.String(), where.args...).Scan(&total)
+	if err != nil {
+		return nil, 0, dbError("count audit log", err)
+	}
+
+	limit, args := where.page(f.ListOptions)
+	rows, err := r.db.QueryContext(ctx, `SELECT id, actor_id, actor, action, entity, entity_id, before, after, created_at
+		FROM audit_log`+where.String()+order+limit, args...)
+	if err != nil {
+		return nil, 0, dbError("list audit log", err)
+	}
+	defer rows.Close()
+
+	var entries []models.AuditEntry
+	for rows.Next() {
+		var e models.AuditEntry
+		var before, after []byte
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.Actor, &e.Action, &e.Entity, &e.EntityID,
+			&before, &after, &e.CreatedAt); err != nil {
+			return nil, 0, dbError("list audit log", err)
+		}
+		e.Before, e.After = nullableJSON(before), nullableJSON(after)
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, dbError("list audit log", err)
+	}
+	return entries, total, nil
+}
+
+// nullableJSON turns an SQL NULL into a JSON null.
+func nullableJSON(b []byte) json.RawMessage {
+	if b == nil {
+		return json.RawMessage("null")
+	}
+	return b
+}
@@ -1,9 +1,13 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"kasir-api/models"
+	"log/slog"
+	"strings"
 )
 
 type CategoryRepository struct {
@@ -14,69 +18,317 @@ func NewCategoryRepository(db *sql.DB) *CategoryRepository {
 	return &CategoryRepository{db: db}
 }
 
-func (r *CategoryRepository) GetAll() ([]models.Category, error) {
-	rows, err := r.db.Query("SELECT id, name, description FROM categories")
+var categorySortColumns = map[string]string{
+	"id":   "id",
+	"name": "name",
+}
+
+// GetAll returns one page of categories along with the total count.
+// Deleted categories are left out unless f.IncludeDeleted is set.
+func (r *CategoryRepository) GetAll(ctx context.Context, f models.CategoryFilter) ([]models.Category, int, error) {
+	order, err := orderBy(f.Sort, categorySortColumns, "id")
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	var where whereClause
+	if !f.IncludeDeleted {
+		where.addRaw("deleted_at IS NULL")
+	}
+	cond := where.String()
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM categories"+cond, where.args...).Scan(&total); err != nil {
+		return nil, 0, dbError("count categories", err)
+	}
+
+	limit, args := where.page(f.ListOptions)
+	rows, err := r.db.QueryContext(ctx, "SELECT id, name, description, version, deleted_at FROM categories"+cond+order+limit, args...)
+	if err != nil {
+		return nil, 0, dbError("list categories", err)
 	}
 	defer rows.Close()
 
 	var categories []models.Category
 	for rows.Next() {
 		var c models.Category
-		if err := rows.Scan(&c.ID, &c.Name, &c.Description); err != nil {
-			return nil, err
+		if err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.Version, &c.DeletedAt); err != nil {
+			return nil, 0, dbError("list categories", err)
 		}
 		categories = append(categories, c)
 	}
-	return categories, nil
+	if err := rows.Err(); err != nil {
+		return nil, 0, dbError("list categories", err)
+	}
+	return categories, total, nil
 }
 
-func (r *CategoryRepository) GetByID(id int) (*models.Category, error) {
+// GetByID loads a category. Deleted categories are not found unless
+// includeDeleted is set.
+func (r *CategoryRepository) GetByID(ctx context.Context, id int, includeDeleted bool) (*models.Category, error) {
+	query := "SELECT id, name, description, version, deleted_at FROM categories WHERE id = $1"
+	if !includeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
 	var c models.Category
-	err := r.db.QueryRow("SELECT id, name, description FROM categories WHERE id = $1", id).
-		Scan(&c.ID, &c.Name, &c.Description)
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("category not found")
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&c.ID, &c.Name, &c.Description, &c.Version, &c.DeletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("category %d: %w", id, ErrNotFound)
 	}
 	if err != nil {
-		return nil, err
+		return nil, dbError("get category", err)
 	}
 	return &c, nil
 }
 
-func (r *CategoryRepository) Create(c *models.Category) error {
-	return r.db.QueryRow("INSERT INTO categories (name, description) VALUES ($1, $2) RETURNING id",
-		c.Name, c.Description).Scan(&c.ID)
+func (r *CategoryRepository) Create(ctx context.Context, c *models.Category, actor models.Actor) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError("create category", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `INSERT INTO categories (name, description) VALUES ($1, $2)
+		RETURNING id, name, description, version, deleted_at`, c.Name, c.Description).
+		Scan(&c.ID, &c.Name, &c.Description, &c.Version, &c.DeletedAt)
+	if err != nil {
+		return dbError("create category", err)
+	}
+	if err := writeAudit(ctx, tx, actor, models.AuditCreate, models.AuditEntityCategory, c.ID, nil, c); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return dbError("create category", err)
+	}
+	return nil
+}
+
+// getForUpdate loads and locks a category row inside tx. Deleted
+// categories are only found when includeDeleted is set.
+func (r *CategoryRepository) getForUpdate(ctx context.Context, tx *sql.Tx, id int, includeDeleted bool) (*models.Category, error) {
+	query := "SELECT id, name, description, version, deleted_at FROM categories WHERE id = $1"
+	if !includeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
+	var c models.Category
+	err := tx.QueryRowContext(ctx, query+" FOR UPDATE", id).Scan(&c.ID, &c.Name, &c.Description, &c.Version, &c.DeletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("category %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, dbError("lock category", err)
+	}
+	return &c, nil
 }
 
-func (r *CategoryRepository) Update(c *models.Category) error {
-	result, err := r.db.Exec("UPDATE categories SET name = $1, description = $2 WHERE id = $3",
-		c.Name, c.Description, c.ID)
+// Update replaces a category's fields. c.Version must match the stored
+// version unless it is 0. c is reloaded from the row on success.
+func (r *CategoryRepository) Update(ctx context.Context, c *models.Category, actor models.Actor) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError("update category", err)
+	}
+	defer tx.Rollback()
+
+	before, err := r.getForUpdate(ctx, tx, c.ID, false)
 	if err != nil {
 		return err
 	}
-	rows, err := result.RowsAffected()
+	if c.Version != 0 && c.Version != before.Version {
+		return fmt.Errorf("category %d: %w", c.ID, ErrVersionMismatch)
+	}
+
+	err = tx.QueryRowContext(ctx, `UPDATE categories SET name = $1, description = $2, version = version + 1
+		WHERE id = $3 RETURNING id, name, description, version, deleted_at`, c.Name, c.Description, c.ID).
+		Scan(&c.ID, &c.Name, &c.Description, &c.Version, &c.DeletedAt)
 	if err != nil {
+		return dbError("update category", err)
+	}
+	if err := writeAudit(ctx, tx, actor, models.AuditUpdate, models.AuditEntityCategory, c.ID, before, c); err != nil {
 		return err
 	}
-	if rows == 0 {
-		return fmt.Errorf("category not found")
+	if err := tx.Commit(); err != nil {
+		return dbError("update category", err)
 	}
 	return nil
 }
 
-func (r *CategoryRepository) Delete(id int) error {
-	result, err := r.db.Exec("DELETE FROM categories WHERE id = $1", id)
+// Patch writes only the named fields of c and reloads c from the row.
+func (r *CategoryRepository) Patch(ctx context.Context, c *models.Category, fields []string, actor models.Actor) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError("patch category", err)
+	}
+	defer tx.Rollback()
+
+	before, err := r.getForUpdate(ctx, tx, c.ID, false)
 	if err != nil {
 		return err
 	}
-	rows, err := result.RowsAffected()
+	if c.Version != 0 && c.Version != before.Version {
+		return fmt.Errorf("category %d: %w", c.ID, ErrVersionMismatch)
+	}
+
+	var set []string
+	var args []any
+	for _, f := range fields {
+		switch f {
+		case "name":
+			args = append(args, c.Name)
+		case "description":
+			args = append(args, c.Description)
+		default:
+			return fmt.Errorf("%w: cannot patch %q", ErrValidation, f)
+		}
+		set = append(set, fmt.Sprintf("%s = $%d", f, len(args)))
+	}
+	if len(set) > 0 {
+		args = append(args, c.ID)
+		query := fmt.Sprintf("UPDATE categories SET %s, version = version + 1 WHERE id = $%d",
+			strings.Join(set, ", "), len(args))
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return dbError("patch category", err)
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, "SELECT id, name, description, version, deleted_at FROM categories WHERE id = $1", c.ID).
+		Scan(&c.ID, &c.Name, &c.Description, &c.Version, &c.DeletedAt)
+	if err != nil {
+		return dbError("patch category", err)
+	}
+	if err := writeAudit(ctx, tx, actor, models.AuditUpdate, models.AuditEntityCategory, c.ID, before, c); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return dbError("patch category", err)
+	}
+	return nil
+}
+
+// Delete soft-deletes a category. With cascade set, its live products are
+// soft-deleted in the same transaction and stamped with the same time, which
+// is how Restore finds them again; otherwise the delete fails with
+// ErrCategoryInUse while live products still point at it. Every deleted row is
+// audited. version must match the category's stored version unless it is 0.
+func (r *CategoryRepository) Delete(ctx context.Context, id, version int, cascade bool, actor models.Actor) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
+		return dbError("delete category", err)
+	}
+	defer tx.Rollback()
+
+	before, err := r.getForUpdate(ctx, tx, id, false)
+	if err != nil {
+		return err
+	}
+	if version != 0 && version != before.Version {
+		return fmt.Errorf("category %d: %w", id, ErrVersionMismatch)
+	}
+
+	var products []models.Product
+	if cascade {
+		products, err = r.setProductsDeleted(ctx, tx, `UPDATE products SET deleted_at = NOW(), version = version + 1
+			WHERE category_id = $1 AND deleted_at IS NULL`, id)
+		if err != nil {
+			return dbError("delete category products", err)
+		}
+		for _, p := range products {
+			if err := writeAudit(ctx, tx, actor, models.AuditDelete, models.AuditEntityProduct, p.ID, p, nil); err != nil {
+				return err
+			}
+		}
+	} else {
+		var n int
+		err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM products WHERE category_id = $1 AND deleted_at IS NULL", id).Scan(&n)
+		if err != nil {
+			return dbError("delete category", err)
+		}
+		if n > 0 {
+			return fmt.Errorf("category %d has %d products: %w", id, n, ErrCategoryInUse)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE categories SET deleted_at = NOW(), version = version + 1 WHERE id = $1", id); err != nil {
+		return dbError("delete category", err)
+	}
+	if err := writeAudit(ctx, tx, actor, models.AuditDelete, models.AuditEntityCategory, id, before, nil); err != nil {
 		return err
 	}
-	if rows == 0 {
-		return fmt.Errorf("category not found")
+	if err := tx.Commit(); err != nil {
+		return dbError("delete category", err)
+	}
+	if cascade {
+		slog.InfoContext(ctx, "category deleted with its products", "category_id", id, "products", len(products))
 	}
 	return nil
 }
+
+// Restore brings back a deleted category together with the products its
+// cascading delete took with it. Products deleted on their own stay
+// deleted. It fails with ErrConflict when the category is not deleted or a
+// restored product's SKU or barcode has been reused.
+func (r *CategoryRepository) Restore(ctx context.Context, id int, actor models.Actor) (*models.Category, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, dbError("restore category", err)
+	}
+	defer tx.Rollback()
+
+	before, err := r.getForUpdate(ctx, tx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if before.DeletedAt == nil {
+		return nil, fmt.Errorf("%w: category %d is not deleted", ErrConflict, id)
+	}
+
+	var c models.Category
+	err = tx.QueryRowContext(ctx, `UPDATE categories SET deleted_at = NULL, version = version + 1
+		WHERE id = $1 RETURNING id, name, description, version, deleted_at`, id).
+		Scan(&c.ID, &c.Name, &c.Description, &c.Version, &c.DeletedAt)
+	if err != nil {
+		return nil, dbError("restore category", err)
+	}
+	products, err := r.setProductsDeleted(ctx, tx, `UPDATE products SET deleted_at = NULL, version = version + 1
+		WHERE category_id = $1 AND deleted_at = $2`, id, *before.DeletedAt)
+	if err != nil {
+		return nil, dbError("restore category products", err)
+	}
+	for _, p := range products {
+		if err := writeAudit(ctx, tx, actor, models.AuditRestore, models.AuditEntityProduct, p.ID, nil, p); err != nil {
+			return nil, err
+		}
+	}
+	if err := writeAudit(ctx, tx, actor, models.AuditRestore, models.AuditEntityCategory, id, before, c); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, dbError("restore category", err)
+	}
+	if len(products) > 0 {
+		slog.InfoContext(ctx, "category restored with its products", "category_id", id, "products", len(products))
+	}
+	return &c, nil
+}
+
+// setProductsDeleted runs an UPDATE of products' deleted_at and returns the
+// rows it changed.
+func (r *CategoryRepository) setProductsDeleted(ctx context.Context, tx *sql.Tx, update string, args ...any) ([]models.Product, error) {
+	rows, err := tx.QueryContext(ctx, update+` RETURNING id, name, price, stock, COALESCE(sku, ''), COALESCE(barcode, ''),
+		category_id, reorder_level, reorder_qty, version, deleted_at`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []models.Product
+	for rows.Next() {
+		var p models.Product
+		if err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.SKU, &p.Barcode, &p.CategoryID,
+			&p.ReorderLevel, &p.ReorderQty, &p.Version, &p.DeletedAt); err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	return products, rows.Err()
+}
@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Sentinel errors returned (wrapped) by every store implementation. Callers
+// should test for them with errors.Is rather than comparing messages.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+)
+
+// ErrVersionMismatch is returned when an update or delete names a version
+// of the row that is no longer current, because someone else changed it.
+var ErrVersionMismatch = errors.New("version mismatch")
+
+// ErrCategoryInUse is a conflict raised when deleting a category that live
+// products still reference and the caller did not ask for a cascading
+// delete.
+var ErrCategoryInUse = fmt.Errorf("%w: category still has products", ErrConflict)
+
+// ErrInsufficientStock is a conflict raised when a sale or adjustment would
+// take a product's stock below zero.
+var ErrInsufficientStock = fmt.Errorf("%w: insufficient stock", ErrConflict)
+
+// dbError classifies Postgres constraint violations as ErrConflict or
+// ErrValidation and wraps everything else with the operation for context.
+func dbError(op string, err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505": // unique_violation
+			return fmt.Errorf("%s: %w: %s", op, ErrConflict, pgErr.Detail)
+		case "23503": // foreign_key_violation
+			// Deleting a referenced row is a conflict; pointing at a missing
+			// row is bad input.
+			if strings.Contains(pgErr.Detail, "is still referenced") {
+				return fmt.Errorf("%s: %w: %s", op, ErrConflict, pgErr.Detail)
+			}
+			return fmt.Errorf("%s: %w: %s", op, ErrValidation, pgErr.Detail)
+		case "23514", "23502": // check_violation, not_null_violation
+			return fmt.Errorf("%s: %w: %s", op, ErrValidation, pgErr.Message)
+		}
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
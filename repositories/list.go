@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"strings"
+	"unicode"
+)
+
+// orderBy renders sort fields as an ORDER BY clause. Names are mapped
+// through columns so user input never reaches the SQL text, and the
+// primary key is always appended so paging is stable.
+func orderBy(sort []models.SortField, columns map[string]string, pk string) (string, error) {
+	parts := make([]string, 0, len(sort)+1)
+	for _, f := range sort {
+		col, ok := columns[f.Name]
+		if !ok {
+			return "", fmt.Errorf("%w: cannot sort by %q", ErrValidation, f.Name)
+		}
+		if f.Desc {
+			col += " DESC"
+		}
+		parts = append(parts, col)
+	}
+	parts = append(parts, pk)
+	return " ORDER BY " + strings.Join(parts, ", "), nil
+}
+
+// whereClause accumulates AND-ed conditions with numbered placeholders.
+type whereClause struct {
+	conds []string
+	args  []any
+}
+
+// add appends cond, whose single %d is replaced by the placeholder for v.
+func (w *whereClause) add(cond string, v any) {
+	w.args = append(w.args, v)
+	w.conds = append(w.conds, fmt.Sprintf(cond, len(w.args)))
+}
+
+func (w *whereClause) addRaw(cond string) {
+	w.conds = append(w.conds, cond)
+}
+
+func (w *whereClause) String() string {
+	if len(w.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(w.conds, " AND ")
+}
+
+// page appends LIMIT/OFFSET placeholders and returns the clause and the
+// full argument list.
+func (w *whereClause) page(o models.ListOptions) (string, []any) {
+	args := append(w.args, o.Limit, o.Offset())
+	return fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args)), args
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike neutralises LIKE wildcards in user input.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// prefixTSQuery turns free text into a to_tsquery expression matching
+// every word as a prefix, e.g. "indo gor" becomes "indo:* & gor:*".
+// Anything but letters and digits is dropped so the result always parses.
+func prefixTSQuery(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+	return strings.Join(words, " & ")
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import "testing"
+
+func TestPrefixTSQuery(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"indo", "indo:*"},
+		{"indo gor", "indo:* & gor:*"},
+		{"  Teh   Botol ", "Teh:* & Botol:*"},
+		{"mie-goreng 2", "mie:* & goreng:* & 2:*"},
+		{"kopi & (susu | !gula):*", "kopi:* & susu:* & gula:*"},
+		{"kécap", "kécap:*"},
+		{"&|!", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := prefixTSQuery(tt.in); got != tt.want {
+			t.Errorf("prefixTSQuery(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
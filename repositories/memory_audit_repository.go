@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"kasir-api/models"
+	"sort"
+)
+
+type MemoryAuditRepository struct {
+	store *MemoryStore
+}
+
+func (r *MemoryAuditRepository) List(_ context.Context, f models.AuditFilter) ([]models.AuditEntry, int, error) {
+	for _, sf := range f.Sort {
+		if _, ok := auditSortColumns[sf.Name]; !ok {
+			return nil, 0, fmt.Errorf("%w: cannot sort by %q", ErrValidation, sf.Name)
+		}
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var entries []models.AuditEntry
+	for _, e := range r.store.auditLog {
+		if f.Entity != "" && e.Entity != f.Entity {
+			continue
+		}
+		if f.EntityID != nil && e.EntityID != *f.EntityID {
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	// Entries are appended in ID order, which is also creation order, so
+	// every supported sort reduces to ascending or descending ID.
+	desc := len(f.Sort) == 0 || f.Sort[0].Desc
+	if desc {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].ID > entries[j].ID })
+	}
+	return paginate(entries, f.ListOptions), len(entries), nil
+}
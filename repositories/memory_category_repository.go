@@ -0,0 +1,213 @@
+package repositories
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"kasir-api/models"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+type MemoryCategoryRepository struct {
+	store *MemoryStore
+}
+
+func (r *MemoryCategoryRepository) GetAll(_ context.Context, f models.CategoryFilter) ([]models.Category, int, error) {
+	for _, sf := range f.Sort {
+		if _, ok := categorySortColumns[sf.Name]; !ok {
+			return nil, 0, fmt.Errorf("%w: cannot sort by %q", ErrValidation, sf.Name)
+		}
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var categories []models.Category
+	for _, c := range r.store.categories {
+		if f.IncludeDeleted || c.DeletedAt == nil {
+			categories = append(categories, c)
+		}
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		a, b := categories[i], categories[j]
+		for _, f := range f.Sort {
+			var c int
+			switch f.Name {
+			case "id":
+				c = cmp.Compare(a.ID, b.ID)
+			case "name":
+				c = strings.Compare(a.Name, b.Name)
+			}
+			if f.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return a.ID < b.ID
+	})
+	return paginate(categories, f.ListOptions), len(categories), nil
+}
+
+func (r *MemoryCategoryRepository) GetByID(_ context.Context, id int, includeDeleted bool) (*models.Category, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	c, ok := r.store.categories[id]
+	if !ok || (c.DeletedAt != nil && !includeDeleted) {
+		return nil, fmt.Errorf("category %d: %w", id, ErrNotFound)
+	}
+	return &c, nil
+}
+
+func (r *MemoryCategoryRepository) Create(_ context.Context, c *models.Category, actor models.Actor) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	c.ID = r.store.nextCategoryID
+	c.Version = 1
+	c.DeletedAt = nil
+	r.store.nextCategoryID++
+	r.store.categories[c.ID] = *c
+	return r.store.audit(actor, models.AuditCreate, models.AuditEntityCategory, c.ID, nil, c)
+}
+
+func (r *MemoryCategoryRepository) Update(_ context.Context, c *models.Category, actor models.Actor) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	before, ok := r.store.liveCategory(c.ID)
+	if !ok {
+		return fmt.Errorf("category %d: %w", c.ID, ErrNotFound)
+	}
+	if c.Version != 0 && c.Version != before.Version {
+		return fmt.Errorf("category %d: %w", c.ID, ErrVersionMismatch)
+	}
+	c.Version = before.Version + 1
+	c.DeletedAt = before.DeletedAt
+	r.store.categories[c.ID] = *c
+	return r.store.audit(actor, models.AuditUpdate, models.AuditEntityCategory, c.ID, before, c)
+}
+
+func (r *MemoryCategoryRepository) Patch(_ context.Context, c *models.Category, fields []string, actor models.Actor) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	before, ok := r.store.liveCategory(c.ID)
+	if !ok {
+		return fmt.Errorf("category %d: %w", c.ID, ErrNotFound)
+	}
+	if c.Version != 0 && c.Version != before.Version {
+		return fmt.Errorf("category %d: %w", c.ID, ErrVersionMismatch)
+	}
+
+	next := before
+	for _, f := range fields {
+		switch f {
+		case "name":
+			next.Name = c.Name
+		case "description":
+			next.Description = c.Description
+		default:
+			return fmt.Errorf("%w: cannot patch %q", ErrValidation, f)
+		}
+	}
+	if len(fields) > 0 {
+		next.Version++
+	}
+	r.store.categories[c.ID] = next
+	*c = next
+	return r.store.audit(actor, models.AuditUpdate, models.AuditEntityCategory, c.ID, before, c)
+}
+
+func (r *MemoryCategoryRepository) Delete(ctx context.Context, id, version int, cascade bool, actor models.Actor) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	before, ok := r.store.liveCategory(id)
+	if !ok {
+		return fmt.Errorf("category %d: %w", id, ErrNotFound)
+	}
+	if version != 0 && version != before.Version {
+		return fmt.Errorf("category %d: %w", id, ErrVersionMismatch)
+	}
+	if n := r.store.countProducts(id); !cascade && n > 0 {
+		return fmt.Errorf("category %d has %d products: %w", id, n, ErrCategoryInUse)
+	}
+	// The products share the category's timestamp so Restore can tell them
+	// from products deleted on their own.
+	now := time.Now()
+	deleted := 0
+	for pid, p := range r.store.products {
+		if p.DeletedAt == nil && p.CategoryID != nil && *p.CategoryID == id {
+			r.store.products[pid] = r.store.deleted(p, now)
+			deleted++
+			if err := r.store.audit(actor, models.AuditDelete, models.AuditEntityProduct, pid, auditProduct(p), nil); err != nil {
+				return err
+			}
+		}
+	}
+	c := before
+	c.DeletedAt = &now
+	c.Version++
+	r.store.categories[id] = c
+	if err := r.store.audit(actor, models.AuditDelete, models.AuditEntityCategory, id, before, nil); err != nil {
+		return err
+	}
+	if cascade {
+		slog.InfoContext(ctx, "category deleted with its products", "category_id", id, "products", deleted)
+	}
+	return nil
+}
+
+func (r *MemoryCategoryRepository) Restore(ctx context.Context, id int, actor models.Actor) (*models.Category, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	before, ok := r.store.categories[id]
+	if !ok {
+		return nil, fmt.Errorf("category %d: %w", id, ErrNotFound)
+	}
+	if before.DeletedAt == nil {
+		return nil, fmt.Errorf("%w: category %d is not deleted", ErrConflict, id)
+	}
+
+	c := before
+	c.DeletedAt = nil
+	c.Version++
+	r.store.categories[id] = c
+
+	// Check every product first so a code clash leaves nothing restored.
+	var products []models.Product
+	for _, p := range r.store.products {
+		if p.DeletedAt != nil && p.DeletedAt.Equal(*before.DeletedAt) && p.CategoryID != nil && *p.CategoryID == id {
+			live := p
+			live.DeletedAt = nil
+			if err := r.store.checkProduct(&live); err != nil {
+				r.store.categories[id] = before
+				return nil, err
+			}
+			products = append(products, p)
+		}
+	}
+	for _, p := range products {
+		restored, err := r.store.restoreProduct(p)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.store.audit(actor, models.AuditRestore, models.AuditEntityProduct, p.ID, nil, auditProduct(restored)); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.store.audit(actor, models.AuditRestore, models.AuditEntityCategory, id, before, c); err != nil {
+		return nil, err
+	}
+	if len(products) > 0 {
+		slog.InfoContext(ctx, "category restored with its products", "category_id", id, "products", len(products))
+	}
+	return &c, nil
+}
@@ -0,0 +1,317 @@
+package repositories
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"kasir-api/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+type MemoryProductRepository struct {
+	store *MemoryStore
+}
+
+func (r *MemoryProductRepository) GetAll(_ context.Context, f models.ProductFilter) ([]models.Product, int, error) {
+	for _, sf := range f.Sort {
+		if _, ok := productSortColumns[sf.Name]; !ok {
+			return nil, 0, fmt.Errorf("%w: cannot sort by %q", ErrValidation, sf.Name)
+		}
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var products []models.Product
+	for _, p := range r.store.products {
+		if (f.IncludeDeleted || p.DeletedAt == nil) && matchProduct(p, f) {
+			products = append(products, r.store.product(p))
+		}
+	}
+	sort.Slice(products, func(i, j int) bool {
+		return compareProducts(products[i], products[j], f.Sort) < 0
+	})
+	return paginate(products, f.ListOptions), len(products), nil
+}
+
+// Search scores substring matches by how much of the name the query
+// covers, with a bonus when it starts a word. It is a rough stand-in for
+// the trigram ranking used on Postgres.
+func (r *MemoryProductRepository) Search(_ context.Context, q string, limit int) ([]models.ProductSearchResult, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	needle := strings.ToLower(q)
+	var results []models.ProductSearchResult
+	for _, p := range r.store.products {
+		if p.DeletedAt != nil {
+			continue
+		}
+		name := strings.ToLower(p.Name)
+		i := strings.Index(name, needle)
+		if i < 0 {
+			continue
+		}
+		score := float64(len(needle)) / float64(len(name))
+		if i == 0 || name[i-1] == ' ' {
+			score = (score + 1) / 2
+		}
+		results = append(results, models.ProductSearchResult{Product: r.store.product(p), Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func matchProduct(p models.Product, f models.ProductFilter) bool {
+	switch {
+	case f.Name != "" && !strings.Contains(strings.ToLower(p.Name), strings.ToLower(f.Name)):
+		return false
+	case f.MinPrice != nil && p.Price < *f.MinPrice:
+		return false
+	case f.MaxPrice != nil && p.Price > *f.MaxPrice:
+		return false
+	case f.InStock != nil && *f.InStock != (p.Stock > 0):
+		return false
+	case f.CategoryID != nil && (p.CategoryID == nil || *p.CategoryID != *f.CategoryID):
+		return false
+	case f.LowStock && !p.LowStock():
+		return false
+	}
+	return true
+}
+
+// compareProducts orders products by the sort fields, breaking ties by ID
+// like the ORDER BY built for Postgres. Products without a category sort
+// last, matching Postgres' NULLS LAST default.
+func compareProducts(a, b models.Product, fields []models.SortField) int {
+	for _, f := range fields {
+		var c int
+		switch f.Name {
+		case "id":
+			c = cmp.Compare(a.ID, b.ID)
+		case "name":
+			c = strings.Compare(a.Name, b.Name)
+		case "price":
+			c = cmp.Compare(a.Price, b.Price)
+		case "stock":
+			c = cmp.Compare(a.Stock, b.Stock)
+		case "category_id":
+			c = compareNullable(a.CategoryID, b.CategoryID)
+		}
+		if f.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+func compareNullable(a, b *int) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	return cmp.Compare(*a, *b)
+}
+
+func (r *MemoryProductRepository) GetByID(_ context.Context, id int, includeDeleted bool) (*models.Product, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	p, ok := r.store.products[id]
+	if !ok || (p.DeletedAt != nil && !includeDeleted) {
+		return nil, fmt.Errorf("product %d: %w", id, ErrNotFound)
+	}
+	p = r.store.product(p)
+	return &p, nil
+}
+
+func (r *MemoryProductRepository) GetByBarcode(_ context.Context, code string) (*models.Product, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, p := range r.store.products {
+		if p.Barcode == code && p.DeletedAt == nil {
+			p = r.store.product(p)
+			return &p, nil
+		}
+	}
+	return nil, fmt.Errorf("product with barcode %s: %w", code, ErrNotFound)
+}
+
+func (r *MemoryProductRepository) Create(_ context.Context, p *models.Product, actor models.Actor) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := r.store.checkProduct(p); err != nil {
+		return err
+	}
+	p.ID = r.store.nextProductID
+	r.store.nextProductID++
+	p.Version = 1
+	p.DeletedAt = nil
+	*p = r.store.product(*p)
+	r.store.products[p.ID] = *p
+	if p.Stock != 0 {
+		m := models.StockMovement{ProductID: p.ID, Kind: models.StockAdjustment, Quantity: p.Stock,
+			Balance: p.Stock, Reason: "initial stock"}
+		r.store.appendMovement(&m, actor)
+	}
+	return r.store.audit(actor, models.AuditCreate, models.AuditEntityProduct, p.ID, nil, auditProduct(*p))
+}
+
+func (r *MemoryProductRepository) Update(_ context.Context, p *models.Product, actor models.Actor) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	before, ok := r.store.liveProduct(p.ID)
+	if !ok {
+		return fmt.Errorf("product %d: %w", p.ID, ErrNotFound)
+	}
+	if p.Version != 0 && p.Version != before.Version {
+		return fmt.Errorf("product %d: %w", p.ID, ErrVersionMismatch)
+	}
+	if err := r.store.checkProduct(p); err != nil {
+		return err
+	}
+	if delta := p.Stock - before.Stock; delta != 0 {
+		m := models.StockMovement{ProductID: p.ID, Kind: models.StockAdjustment, Quantity: delta, Reason: "set by product update"}
+		if err := r.store.moveStock(&m, actor); err != nil {
+			return err
+		}
+	}
+	p.Version = r.store.products[p.ID].Version + 1
+	p.DeletedAt = before.DeletedAt
+	*p = r.store.product(*p)
+	r.store.products[p.ID] = *p
+	return r.store.audit(actor, models.AuditUpdate, models.AuditEntityProduct, p.ID, auditProduct(before), auditProduct(*p))
+}
+
+func (r *MemoryProductRepository) Patch(_ context.Context, p *models.Product, fields []string, actor models.Actor) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	before, ok := r.store.liveProduct(p.ID)
+	if !ok {
+		return fmt.Errorf("product %d: %w", p.ID, ErrNotFound)
+	}
+	if p.Version != 0 && p.Version != before.Version {
+		return fmt.Errorf("product %d: %w", p.ID, ErrVersionMismatch)
+	}
+
+	next := before
+	for _, f := range fields {
+		switch f {
+		case "name":
+			next.Name = p.Name
+		case "price":
+			next.Price = p.Price
+		case "stock":
+			next.Stock = p.Stock
+		case "sku":
+			next.SKU = p.SKU
+		case "barcode":
+			next.Barcode = p.Barcode
+		case "category_id":
+			next.CategoryID = p.CategoryID
+		case "reorder_level":
+			next.ReorderLevel = p.ReorderLevel
+		case "reorder_qty":
+			next.ReorderQty = p.ReorderQty
+		default:
+			return fmt.Errorf("%w: cannot patch %q", ErrValidation, f)
+		}
+	}
+	if err := r.store.checkProduct(&next); err != nil {
+		return err
+	}
+	if delta := next.Stock - before.Stock; delta != 0 {
+		m := models.StockMovement{ProductID: p.ID, Kind: models.StockAdjustment, Quantity: delta, Reason: "set by product patch"}
+		if err := r.store.moveStock(&m, actor); err != nil {
+			return err
+		}
+	}
+	if len(fields) > 0 {
+		next.Version = r.store.products[p.ID].Version + 1
+	}
+	r.store.products[p.ID] = r.store.product(next)
+	*p = r.store.product(next)
+	return r.store.audit(actor, models.AuditUpdate, models.AuditEntityProduct, p.ID, auditProduct(before), auditProduct(*p))
+}
+
+func (r *MemoryProductRepository) Delete(_ context.Context, id, version int, actor models.Actor) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	before, ok := r.store.liveProduct(id)
+	if !ok {
+		return fmt.Errorf("product %d: %w", id, ErrNotFound)
+	}
+	if version != 0 && version != before.Version {
+		return fmt.Errorf("product %d: %w", id, ErrVersionMismatch)
+	}
+	r.store.products[id] = r.store.deleted(before, time.Now())
+	return r.store.audit(actor, models.AuditDelete, models.AuditEntityProduct, id, auditProduct(before), nil)
+}
+
+func (r *MemoryProductRepository) Restore(_ context.Context, id int, actor models.Actor) (*models.Product, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	before, ok := r.store.products[id]
+	if !ok {
+		return nil, fmt.Errorf("product %d: %w", id, ErrNotFound)
+	}
+	if before.DeletedAt == nil {
+		return nil, fmt.Errorf("%w: product %d is not deleted", ErrConflict, id)
+	}
+	if before.CategoryID != nil {
+		if _, ok := r.store.liveCategory(*before.CategoryID); !ok {
+			return nil, fmt.Errorf("%w: category %d is deleted; restore it first", ErrConflict, *before.CategoryID)
+		}
+	}
+	p, err := r.store.restoreProduct(before)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.store.audit(actor, models.AuditRestore, models.AuditEntityProduct, id, auditProduct(before), auditProduct(p)); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// deleted returns p stamped as deleted at t with its version bumped.
+func (s *MemoryStore) deleted(p models.Product, t time.Time) models.Product {
+	p.DeletedAt = &t
+	p.Version++
+	return p
+}
+
+// restoreProduct clears a deleted product's DeletedAt, failing with
+// ErrConflict if a live product has taken its SKU or barcode, as the
+// partial unique indexes do in Postgres. Callers must hold the write lock.
+func (s *MemoryStore) restoreProduct(p models.Product) (models.Product, error) {
+	p.DeletedAt = nil
+	if err := s.checkProduct(&p); err != nil {
+		return models.Product{}, err
+	}
+	p.Version++
+	s.products[p.ID] = p
+	return s.product(p), nil
+}
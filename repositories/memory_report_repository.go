@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"kasir-api/models"
+	"time"
+)
+
+type MemoryReportRepository struct {
+	store *MemoryStore
+}
+
+// GetSalesSummary mirrors ReportRepository.GetSalesSummary over the
+// in-memory transactions. Ties for best seller go to the lower product ID.
+func (r *MemoryReportRepository) GetSalesSummary(_ context.Context, from, to time.Time) (*models.SalesReport, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var report models.SalesReport
+	sold := make(map[int]*models.BestSeller)
+	for _, trx := range r.store.transactions {
+		if trx.CreatedAt.Before(from) || !trx.CreatedAt.Before(to) {
+			continue
+		}
+		report.TotalRevenue += trx.TotalAmount
+		report.TotalTransactions++
+		for _, d := range trx.Details {
+			b, ok := sold[d.ProductID]
+			if !ok {
+				b = &models.BestSeller{ProductID: d.ProductID}
+				sold[d.ProductID] = b
+			}
+			b.Name = max(b.Name, d.ProductName)
+			b.QuantitySold += d.Quantity
+		}
+	}
+
+	for _, b := range sold {
+		best := report.BestSeller
+		if best == nil || b.QuantitySold > best.QuantitySold ||
+			(b.QuantitySold == best.QuantitySold && b.ProductID < best.ProductID) {
+			report.BestSeller = b
+		}
+	}
+	return &report, nil
+}
@@ -0,0 +1,199 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"kasir-api/models"
+	"sync"
+	"time"
+)
+
+// MemoryStore holds products, categories, users, the stock ledger, sales
+// and the audit log in process memory. The repositories share one lock so cross-entity rules (a
+// product's category must exist, a category in use cannot be dropped) hold
+// atomically. Deleted products and categories stay in their maps with
+// DeletedAt set.
+type MemoryStore struct {
+	mu             sync.RWMutex
+	products       map[int]models.Product
+	categories     map[int]models.Category
+	nextProductID  int
+	nextCategoryID int
+
+	users         map[int]models.User
+	refreshTokens map[string]memoryRefreshToken
+	nextUserID    int
+
+	stockMovements []models.StockMovement
+	nextMovementID int64
+
+	transactions      []models.Transaction
+	nextTransactionID int
+	nextDetailID      int
+
+	auditLog []models.AuditEntry
+}
+
+type memoryRefreshToken struct {
+	userID    int
+	expiresAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		products:       make(map[int]models.Product),
+		categories:     make(map[int]models.Category),
+		nextProductID:  1,
+		nextCategoryID: 1,
+		users:          make(map[int]models.User),
+		refreshTokens:  make(map[string]memoryRefreshToken),
+		nextUserID:     1,
+	}
+}
+
+// SeedDemo fills the store with a small sample catalogue.
+func (s *MemoryStore) SeedDemo() {
+	categories := s.Categories()
+	products := s.Products()
+
+	food := models.Category{Name: "Makanan", Description: "Makanan instan dan bumbu"}
+	drink := models.Category{Name: "Minuman", Description: "Minuman kemasan"}
+	system := models.Actor{Username: "system"}
+	ctx := context.Background()
+	categories.Create(ctx, &food, system)
+	categories.Create(ctx, &drink, system)
+
+	products.Create(ctx, &models.Product{Name: "Indomie Goreng", Price: 3500, Stock: 100, SKU: "MKN-001",
+		Barcode: "0089686010947", CategoryID: &food.ID}, system)
+	products.Create(ctx, &models.Product{Name: "Teh Botol", Price: 3000, Stock: 50, SKU: "MNM-001",
+		Barcode: "8886008101053", CategoryID: &drink.ID}, system)
+	products.Create(ctx, &models.Product{Name: "Kecap Bango", Price: 12000, Stock: 20, SKU: "MKN-002",
+		CategoryID: &food.ID, ReorderLevel: 24, ReorderQty: 48}, system)
+}
+
+func (s *MemoryStore) Products() *MemoryProductRepository {
+	return &MemoryProductRepository{store: s}
+}
+
+func (s *MemoryStore) Categories() *MemoryCategoryRepository {
+	return &MemoryCategoryRepository{store: s}
+}
+
+func (s *MemoryStore) Users() *MemoryUserRepository {
+	return &MemoryUserRepository{store: s}
+}
+
+func (s *MemoryStore) Stock() *MemoryStockRepository {
+	return &MemoryStockRepository{store: s}
+}
+
+func (s *MemoryStore) Transactions() *MemoryTransactionRepository {
+	return &MemoryTransactionRepository{store: s}
+}
+
+func (s *MemoryStore) Reports() *MemoryReportRepository {
+	return &MemoryReportRepository{store: s}
+}
+
+func (s *MemoryStore) Audit() *MemoryAuditRepository {
+	return &MemoryAuditRepository{store: s}
+}
+
+// product returns a copy of a stored product with its category name filled
+// in, mirroring the JOIN in ProductRepository. Callers must hold the lock.
+func (s *MemoryStore) product(p models.Product) models.Product {
+	p.CategoryName = ""
+	if p.CategoryID != nil {
+		id := *p.CategoryID
+		p.CategoryID = &id
+		p.CategoryName = s.categories[id].Name
+	}
+	return p
+}
+
+// liveProduct returns a stored product unless it is missing or deleted.
+// Callers must hold the lock.
+func (s *MemoryStore) liveProduct(id int) (models.Product, bool) {
+	p, ok := s.products[id]
+	return p, ok && p.DeletedAt == nil
+}
+
+// liveCategory returns a stored category unless it is missing or deleted.
+// Callers must hold the lock.
+func (s *MemoryStore) liveCategory(id int) (models.Category, bool) {
+	c, ok := s.categories[id]
+	return c, ok && c.DeletedAt == nil
+}
+
+// checkProduct enforces the constraints Postgres would: the category must
+// exist and SKU and barcode must be unique among live products. Callers
+// must hold the lock.
+func (s *MemoryStore) checkProduct(p *models.Product) error {
+	if p.CategoryID != nil {
+		if _, ok := s.liveCategory(*p.CategoryID); !ok {
+			return fmt.Errorf("%w: category %d does not exist", ErrValidation, *p.CategoryID)
+		}
+	}
+	for _, other := range s.products {
+		if other.ID == p.ID || other.DeletedAt != nil {
+			continue
+		}
+		if p.SKU != "" && other.SKU == p.SKU {
+			return fmt.Errorf("%w: sku %s already exists", ErrConflict, p.SKU)
+		}
+		if p.Barcode != "" && other.Barcode == p.Barcode {
+			return fmt.Errorf("%w: barcode %s already exists", ErrConflict, p.Barcode)
+		}
+	}
+	return nil
+}
+
+// countProducts counts live products in a category. Callers must hold the
+// lock.
+func (s *MemoryStore) countProducts(categoryID int) int {
+	n := 0
+	for _, p := range s.products {
+		if p.DeletedAt == nil && p.CategoryID != nil && *p.CategoryID == categoryID {
+			n++
+		}
+	}
+	return n
+}
+
+// paginate returns the page of items selected by o.
+func paginate[T any](items []T, o models.ListOptions) []T {
+	start := max(min(o.Offset(), len(items)), 0)
+	end := min(start+o.Limit, len(items))
+	return items[start:end]
+}
+
+// audit appends an entry to the in-memory audit log. Callers must hold the
+// write lock.
+func (s *MemoryStore) audit(actor models.Actor, action, entity string, entityID int, before, after any) error {
+	beforeJSON, err := auditJSON(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := auditJSON(after)
+	if err != nil {
+		return err
+	}
+
+	var actorID *int
+	if actor.ID != 0 {
+		id := actor.ID
+		actorID = &id
+	}
+	s.auditLog = append(s.auditLog, models.AuditEntry{
+		ID:        int64(len(s.auditLog) + 1),
+		ActorID:   actorID,
+		Actor:     actor.Username,
+		Action:    action,
+		Entity:    entity,
+		EntityID:  entityID,
+		Before:    nullableJSON(beforeJSON),
+		After:     nullableJSON(afterJSON),
+		CreatedAt: time.Now(),
+	})
+	return nil
+}
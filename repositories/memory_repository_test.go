@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"kasir-api/models"
+	"slices"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	tests := []struct {
+		name string
+		opts models.ListOptions
+		want []int
+	}{
+		{"first page", models.ListOptions{Page: 1, Limit: 2}, []int{1, 2}},
+		{"last partial page", models.ListOptions{Page: 3, Limit: 2}, []int{5}},
+		{"past the end", models.ListOptions{Page: 4, Limit: 2}, []int{}},
+		{"negative offset", models.ListOptions{Page: 0, Limit: 2}, []int{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := paginate(items, tt.opts); !slices.Equal(got, tt.want) {
+				t.Errorf("paginate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"kasir-api/models"
+	"sort"
+	"time"
+)
+
+type MemoryStockRepository struct {
+	store *MemoryStore
+}
+
+func (r *MemoryStockRepository) Adjust(_ context.Context, m *models.StockMovement, actor models.Actor) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.liveProduct(m.ProductID); !ok {
+		return fmt.Errorf("product %d: %w", m.ProductID, ErrNotFound)
+	}
+	return r.store.moveStock(m, actor)
+}
+
+func (r *MemoryStockRepository) History(_ context.Context, productID int, f models.StockMovementFilter) ([]models.StockMovement, int, error) {
+	for _, sf := range f.Sort {
+		if _, ok := stockMovementSortColumns[sf.Name]; !ok {
+			return nil, 0, fmt.Errorf("%w: cannot sort by %q", ErrValidation, sf.Name)
+		}
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if _, ok := r.store.products[productID]; !ok {
+		return nil, 0, fmt.Errorf("product %d: %w", productID, ErrNotFound)
+	}
+	var movements []models.StockMovement
+	for _, m := range r.store.stockMovements {
+		if m.ProductID != productID || (f.Kind != "" && m.Kind != f.Kind) {
+			continue
+		}
+		movements = append(movements, m)
+	}
+
+	// As with the audit log, ID order is creation order.
+	desc := len(f.Sort) == 0 || f.Sort[0].Desc
+	if desc {
+		sort.Slice(movements, func(i, j int) bool { return movements[i].ID > movements[j].ID })
+	}
+	return paginate(movements, f.ListOptions), len(movements), nil
+}
+
+// moveStock applies m to the stored product, bumping its version, and
+// appends it to the ledger, mirroring recordMovement. Callers must hold the
+// write lock and have checked that the product exists.
+func (s *MemoryStore) moveStock(m *models.StockMovement, actor models.Actor) error {
+	p := s.products[m.ProductID]
+	if p.Stock+m.Quantity < 0 {
+		return fmt.Errorf("%w: product %d", ErrInsufficientStock, m.ProductID)
+	}
+	p.Stock += m.Quantity
+	p.Version++
+	s.products[p.ID] = p
+
+	m.Balance = p.Stock
+	s.appendMovement(m, actor)
+	return nil
+}
+
+// appendMovement adds m, whose Balance is already set, to the ledger.
+// Callers must hold the write lock.
+func (s *MemoryStore) appendMovement(m *models.StockMovement, actor models.Actor) {
+	s.nextMovementID++
+	m.ID = s.nextMovementID
+	m.Actor = actor.Username
+	if actor.ID != 0 {
+		id := actor.ID
+		m.ActorID = &id
+	}
+	m.CreatedAt = time.Now()
+	s.stockMovements = append(s.stockMovements, *m)
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"kasir-api/models"
+	"time"
+)
+
+type MemoryTransactionRepository struct {
+	store *MemoryStore
+}
+
+// CreateTransaction mirrors TransactionRepository.CreateTransaction. The
+// whole cart is checked under the write lock before any stock moves, so a
+// failed checkout leaves the store untouched.
+func (r *MemoryTransactionRepository) CreateTransaction(_ context.Context, items []models.CheckoutItem, actor models.Actor) (*models.Transaction, []models.LowStockAlert, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var trx models.Transaction
+	var before []models.Product
+	for _, item := range items {
+		p, ok := r.store.liveProduct(item.ProductID)
+		if !ok {
+			return nil, nil, fmt.Errorf("product %d: %w", item.ProductID, ErrNotFound)
+		}
+		if p.Stock < item.Quantity {
+			return nil, nil, fmt.Errorf("%w: %s has %d, want %d", ErrInsufficientStock, p.Name, p.Stock, item.Quantity)
+		}
+		before = append(before, p)
+
+		subtotal := p.Price * item.Quantity
+		trx.TotalAmount += subtotal
+		trx.Details = append(trx.Details, models.TransactionDetail{
+			ProductID:   p.ID,
+			ProductName: p.Name,
+			Quantity:    item.Quantity,
+			Price:       p.Price,
+			Subtotal:    subtotal,
+		})
+	}
+
+	r.store.nextTransactionID++
+	trx.ID = r.store.nextTransactionID
+	trx.CreatedAt = time.Now()
+
+	var alerts []models.LowStockAlert
+	for i := range trx.Details {
+		d := &trx.Details[i]
+		r.store.nextDetailID++
+		d.ID = r.store.nextDetailID
+		d.TransactionID = trx.ID
+
+		m := models.StockMovement{
+			ProductID:     d.ProductID,
+			Kind:          models.StockSale,
+			Quantity:      -d.Quantity,
+			TransactionID: &trx.ID,
+		}
+		if err := r.store.moveStock(&m, actor); err != nil {
+			return nil, nil, err
+		}
+		if alert, ok := saleAlert(&before[i], m, trx.ID); ok {
+			alerts = append(alerts, alert)
+		}
+	}
+
+	r.store.transactions = append(r.store.transactions, trx)
+	return cloneTransaction(trx), alerts, nil
+}
+
+// cloneTransaction copies trx so callers cannot modify the stored details.
+func cloneTransaction(trx models.Transaction) *models.Transaction {
+	trx.Details = append([]models.TransactionDetail(nil), trx.Details...)
+	return &trx
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"kasir-api/models"
+	"time"
+)
+
+type MemoryUserRepository struct {
+	store *MemoryStore
+}
+
+func (r *MemoryUserRepository) GetByUsername(_ context.Context, username string) (*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, u := range r.store.users {
+		if u.Username == username {
+			return &u, nil
+		}
+	}
+	return nil, fmt.Errorf("user %s: %w", username, ErrNotFound)
+}
+
+func (r *MemoryUserRepository) GetByID(_ context.Context, id int) (*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	u, ok := r.store.users[id]
+	if !ok {
+		return nil, fmt.Errorf("user %d: %w", id, ErrNotFound)
+	}
+	return &u, nil
+}
+
+func (r *MemoryUserRepository) Create(_ context.Context, u *models.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, other := range r.store.users {
+		if other.Username == u.Username {
+			return fmt.Errorf("%w: username %s already exists", ErrConflict, u.Username)
+		}
+	}
+	u.ID = r.store.nextUserID
+	r.store.nextUserID++
+	u.CreatedAt = time.Now()
+	r.store.users[u.ID] = *u
+	return nil
+}
+
+func (r *MemoryUserRepository) CreateRefreshToken(_ context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.refreshTokens[tokenHash] = memoryRefreshToken{userID: userID, expiresAt: expiresAt}
+	return nil
+}
+
+func (r *MemoryUserRepository) ConsumeRefreshToken(_ context.Context, tokenHash string) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	t, ok := r.store.refreshTokens[tokenHash]
+	delete(r.store.refreshTokens, tokenHash)
+	if !ok || time.Now().After(t.expiresAt) {
+		return 0, fmt.Errorf("refresh token: %w", ErrNotFound)
+	}
+	return t.userID, nil
+}
@@ -1,9 +1,12 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"kasir-api/models"
+	"strings"
 )
 
 type ProductRepository struct {
@@ -14,69 +17,446 @@ func NewProductRepository(db *sql.DB) *ProductRepository {
 	return &ProductRepository{db: db}
 }
 
-func (r *ProductRepository) GetAll() ([]models.Product, error) {
-	rows, err := r.db.Query("SELECT id, name, price, stock FROM products")
+// productSelect joins the category so reads carry its name alongside the ID.
+const productSelect = `SELECT p.id, p.name, p.price, p.stock, COALESCE(p.sku, ''), COALESCE(p.barcode, ''),
+	p.category_id, COALESCE(c.name, ''), p.reorder_level, p.reorder_qty, p.version, p.deleted_at
+	FROM products p LEFT JOIN categories c ON c.id = p.category_id`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanProduct(row rowScanner, p *models.Product) error {
+	return row.Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.SKU, &p.Barcode, &p.CategoryID, &p.CategoryName,
+		&p.ReorderLevel, &p.ReorderQty, &p.Version, &p.DeletedAt)
+}
+
+var productSortColumns = map[string]string{
+	"id":          "p.id",
+	"name":        "p.name",
+	"price":       "p.price",
+	"stock":       "p.stock",
+	"category_id": "p.category_id",
+}
+
+// GetAll returns one page of products matching f along with the total
+// number of matches across all pages.
+func (r *ProductRepository) GetAll(ctx context.Context, f models.ProductFilter) ([]models.Product, int, error) {
+	var where whereClause
+	if !f.IncludeDeleted {
+		where.addRaw("p.deleted_at IS NULL")
+	}
+	if f.Name != "" {
+		where.add("p.name ILIKE $%d", "%"+escapeLike(f.Name)+"%")
+	}
+	if f.MinPrice != nil {
+		where.add("p.price >= $%d", *f.MinPrice)
+	}
+	if f.MaxPrice != nil {
+		where.add("p.price <= $%d", *f.MaxPrice)
+	}
+	if f.InStock != nil {
+		if *f.InStock {
+			where.addRaw("p.stock > 0")
+		} else {
+			where.addRaw("p.stock = 0")
+		}
+	}
+	if f.CategoryID != nil {
+		where.add("p.category_id = $%d", *f.CategoryID)
+	}
+	if f.LowStock {
+		where.addRaw("p.stock < p.reorder_level")
+	}
+
+	order, err := orderBy(f.Sort, productSortColumns, "p.id")
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	var total int
+	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM products p"+where.String(), where.args...).Scan(&total)
+	if err != nil {
+		return nil, 0, dbError("count products", err)
+	}
+
+	limit, args := where.page(f.ListOptions)
+	products, err := r.query(ctx, productSelect+where.String()+order+limit, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
+// Search ranks products against a partial name typed at the till. Trigram
+// word similarity catches fragments and typos ("indom"), while the
+// full-text prefix query rewards whole-word matches; the better of the two
+// is the score.
+func (r *ProductRepository) Search(ctx context.Context, q string, limit int) ([]models.ProductSearchResult, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT p.id, p.name, p.price, p.stock, COALESCE(p.sku, ''), COALESCE(p.barcode, ''),
+			p.category_id, COALESCE(c.name, ''), p.reorder_level, p.reorder_qty, p.version, p.deleted_at,
+			GREATEST(word_similarity($1, p.name),
+				ts_rank(to_tsvector('simple', p.name), to_tsquery('simple', $2))) AS score
+		FROM products p LEFT JOIN categories c ON c.id = p.category_id
+		WHERE p.deleted_at IS NULL
+			AND ($1 <% p.name
+				OR to_tsvector('simple', p.name) @@ to_tsquery('simple', $2)
+				OR p.name ILIKE $3)
+		ORDER BY score DESC, p.id
+		LIMIT $4`,
+		q, prefixTSQuery(q), "%"+escapeLike(q)+"%", limit)
+	if err != nil {
+		return nil, dbError("search products", err)
+	}
+	defer rows.Close()
+
+	var results []models.ProductSearchResult
+	for rows.Next() {
+		var res models.ProductSearchResult
+		p := &res.Product
+		if err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.SKU, &p.Barcode,
+			&p.CategoryID, &p.CategoryName, &p.ReorderLevel, &p.ReorderQty, &p.Version, &p.DeletedAt, &res.Score); err != nil {
+			return nil, dbError("search products", err)
+		}
+		results = append(results, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError("search products", err)
+	}
+	return results, nil
+}
+
+func (r *ProductRepository) query(ctx context.Context, query string, args ...any) ([]models.Product, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError("list products", err)
 	}
 	defer rows.Close()
 
 	var products []models.Product
 	for rows.Next() {
 		var p models.Product
-		if err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Stock); err != nil {
-			return nil, err
+		if err := scanProduct(rows, &p); err != nil {
+			return nil, dbError("list products", err)
 		}
 		products = append(products, p)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError("list products", err)
+	}
 	return products, nil
 }
 
-func (r *ProductRepository) GetByID(id int) (*models.Product, error) {
+// GetByID loads a product. Soft-deleted products are not found unless
+// includeDeleted is set.
+func (r *ProductRepository) GetByID(ctx context.Context, id int, includeDeleted bool) (*models.Product, error) {
+	query := productSelect + " WHERE p.id = $1"
+	if !includeDeleted {
+		query += " AND p.deleted_at IS NULL"
+	}
 	var p models.Product
-	err := r.db.QueryRow("SELECT id, name, price, stock FROM products WHERE id = $1", id).
-		Scan(&p.ID, &p.Name, &p.Price, &p.Stock)
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("product not found")
+	err := scanProduct(r.db.QueryRowContext(ctx, query, id), &p)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("product %d: %w", id, ErrNotFound)
 	}
 	if err != nil {
-		return nil, err
+		return nil, dbError("get product", err)
+	}
+	return &p, nil
+}
+
+// GetByBarcode looks up a product by its normalised EAN-13 barcode.
+func (r *ProductRepository) GetByBarcode(ctx context.Context, code string) (*models.Product, error) {
+	var p models.Product
+	err := scanProduct(r.db.QueryRowContext(ctx, productSelect+" WHERE p.barcode = $1 AND p.deleted_at IS NULL", code), &p)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("product with barcode %s: %w", code, ErrNotFound)
+	}
+	if err != nil {
+		return nil, dbError("get product by barcode", err)
 	}
 	return &p, nil
 }
 
-func (r *ProductRepository) Create(p *models.Product) error {
-	return r.db.QueryRow("INSERT INTO products (name, price, stock) VALUES ($1, $2, $3) RETURNING id",
-		p.Name, p.Price, p.Stock).Scan(&p.ID)
+// auditProduct is the snapshot written to the audit log: the product's own
+// columns, without the joined category name.
+func auditProduct(p models.Product) models.Product {
+	p.CategoryName = ""
+	return p
+}
+
+// checkCategory rejects a category that does not exist or is deleted; the
+// foreign key alone cannot see soft deletes. The row is share-locked so it
+// cannot be deleted before tx commits.
+func checkCategory(ctx context.Context, tx *sql.Tx, id *int) error {
+	if id == nil {
+		return nil
+	}
+	var found int
+	err := tx.QueryRowContext(ctx, "SELECT id FROM categories WHERE id = $1 AND deleted_at IS NULL FOR SHARE", *id).Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: category %d does not exist", ErrValidation, *id)
+	}
+	if err != nil {
+		return dbError("check category", err)
+	}
+	return nil
+}
+
+func (r *ProductRepository) Create(ctx context.Context, p *models.Product, actor models.Actor) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError("create product", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkCategory(ctx, tx, p.CategoryID); err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `INSERT INTO products
+		(name, price, stock, sku, barcode, category_id, reorder_level, reorder_qty)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8) RETURNING id`,
+		p.Name, p.Price, p.Stock, p.SKU, p.Barcode, p.CategoryID, p.ReorderLevel, p.ReorderQty).Scan(&p.ID)
+	if err != nil {
+		return dbError("create product", err)
+	}
+	if err := scanProduct(tx.QueryRowContext(ctx, productSelect+" WHERE p.id = $1", p.ID), p); err != nil {
+		return dbError("create product", err)
+	}
+	// The opening stock is the ledger's first entry.
+	if p.Stock != 0 {
+		m := models.StockMovement{ProductID: p.ID, Kind: models.StockAdjustment, Quantity: p.Stock,
+			Balance: p.Stock, Reason: "initial stock"}
+		if err := insertMovement(ctx, tx, &m, actor); err != nil {
+			return err
+		}
+	}
+	if err := writeAudit(ctx, tx, actor, models.AuditCreate, models.AuditEntityProduct, p.ID, nil, auditProduct(*p)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return dbError("create product", err)
+	}
+	return nil
 }
 
-func (r *ProductRepository) Update(p *models.Product) error {
-	result, err := r.db.Exec("UPDATE products SET name = $1, price = $2, stock = $3 WHERE id = $4",
-		p.Name, p.Price, p.Stock, p.ID)
+// Update replaces a product's fields. p.Version must match the stored
+// version unless it is 0. p is reloaded from the row on success.
+func (r *ProductRepository) Update(ctx context.Context, p *models.Product, actor models.Actor) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError("update product", err)
+	}
+	defer tx.Rollback()
+
+	before, err := r.GetForUpdate(ctx, tx, p.ID)
 	if err != nil {
 		return err
 	}
-	rows, err := result.RowsAffected()
+	if p.Version != 0 && p.Version != before.Version {
+		return fmt.Errorf("product %d: %w", p.ID, ErrVersionMismatch)
+	}
+	if err := checkCategory(ctx, tx, p.CategoryID); err != nil {
+		return err
+	}
+
+	// A changed stock count is booked as an adjustment rather than
+	// overwritten, so the ledger still explains the new figure.
+	if delta := p.Stock - before.Stock; delta != 0 {
+		m := models.StockMovement{ProductID: p.ID, Kind: models.StockAdjustment, Quantity: delta, Reason: "set by product update"}
+		if err := recordMovement(ctx, tx, &m, actor); err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE products SET name = $1, price = $2,
+		sku = NULLIF($3, ''), barcode = NULLIF($4, ''), category_id = $5,
+		reorder_level = $6, reorder_qty = $7, version = version + 1 WHERE id = $8`,
+		p.Name, p.Price, p.SKU, p.Barcode, p.CategoryID, p.ReorderLevel, p.ReorderQty, p.ID)
 	if err != nil {
+		return dbError("update product", err)
+	}
+	if err := scanProduct(tx.QueryRowContext(ctx, productSelect+" WHERE p.id = $1", p.ID), p); err != nil {
+		return dbError("update product", err)
+	}
+	if err := writeAudit(ctx, tx, actor, models.AuditUpdate, models.AuditEntityProduct, p.ID, before, auditProduct(*p)); err != nil {
 		return err
 	}
-	if rows == 0 {
-		return fmt.Errorf("product not found")
+	if err := tx.Commit(); err != nil {
+		return dbError("update product", err)
 	}
 	return nil
 }
 
-func (r *ProductRepository) Delete(id int) error {
-	result, err := r.db.Exec("DELETE FROM products WHERE id = $1", id)
+// productPatchColumns maps the patchable JSON fields of a product, except
+// stock, to their SET clause and value.
+var productPatchColumns = map[string]struct {
+	set   string
+	value func(p *models.Product) any
+}{
+	"name":          {"name = $%d", func(p *models.Product) any { return p.Name }},
+	"price":         {"price = $%d", func(p *models.Product) any { return p.Price }},
+	"sku":           {"sku = NULLIF($%d, '')", func(p *models.Product) any { return p.SKU }},
+	"barcode":       {"barcode = NULLIF($%d, '')", func(p *models.Product) any { return p.Barcode }},
+	"category_id":   {"category_id = $%d", func(p *models.Product) any { return p.CategoryID }},
+	"reorder_level": {"reorder_level = $%d", func(p *models.Product) any { return p.ReorderLevel }},
+	"reorder_qty":   {"reorder_qty = $%d", func(p *models.Product) any { return p.ReorderQty }},
+}
+
+// Patch writes only the named fields of p, leaving the other columns as
+// they are in the database. A stock change goes through the ledger, as in
+// Update. p is reloaded from the row afterwards.
+func (r *ProductRepository) Patch(ctx context.Context, p *models.Product, fields []string, actor models.Actor) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
+		return dbError("patch product", err)
+	}
+	defer tx.Rollback()
+
+	before, err := r.GetForUpdate(ctx, tx, p.ID)
+	if err != nil {
+		return err
+	}
+	if p.Version != 0 && p.Version != before.Version {
+		return fmt.Errorf("product %d: %w", p.ID, ErrVersionMismatch)
+	}
+
+	var set []string
+	var args []any
+	for _, f := range fields {
+		if f == "stock" {
+			if delta := p.Stock - before.Stock; delta != 0 {
+				m := models.StockMovement{ProductID: p.ID, Kind: models.StockAdjustment, Quantity: delta, Reason: "set by product patch"}
+				if err := recordMovement(ctx, tx, &m, actor); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if f == "category_id" {
+			if err := checkCategory(ctx, tx, p.CategoryID); err != nil {
+				return err
+			}
+		}
+		col, ok := productPatchColumns[f]
+		if !ok {
+			return fmt.Errorf("%w: cannot patch %q", ErrValidation, f)
+		}
+		args = append(args, col.value(p))
+		set = append(set, fmt.Sprintf(col.set, len(args)))
+	}
+	if len(set) > 0 {
+		args = append(args, p.ID)
+		query := fmt.Sprintf("UPDATE products SET %s, version = version + 1 WHERE id = $%d",
+			strings.Join(set, ", "), len(args))
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return dbError("patch product", err)
+		}
+	}
+
+	if err := scanProduct(tx.QueryRowContext(ctx, productSelect+" WHERE p.id = $1", p.ID), p); err != nil {
+		return dbError("patch product", err)
+	}
+	if err := writeAudit(ctx, tx, actor, models.AuditUpdate, models.AuditEntityProduct, p.ID, before, auditProduct(*p)); err != nil {
 		return err
 	}
-	rows, err := result.RowsAffected()
+	if err := tx.Commit(); err != nil {
+		return dbError("patch product", err)
+	}
+	return nil
+}
+
+// Delete soft-deletes a product: the row stays for the sales and stock
+// movements that reference it. version must match the stored version unless
+// it is 0.
+func (r *ProductRepository) Delete(ctx context.Context, id, version int, actor models.Actor) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError("delete product", err)
+	}
+	defer tx.Rollback()
+
+	before, err := r.GetForUpdate(ctx, tx, id)
 	if err != nil {
 		return err
 	}
-	if rows == 0 {
-		return fmt.Errorf("product not found")
+	if version != 0 && version != before.Version {
+		return fmt.Errorf("product %d: %w", id, ErrVersionMismatch)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE products SET deleted_at = NOW(), version = version + 1 WHERE id = $1", id)
+	if err != nil {
+		return dbError("delete product", err)
+	}
+	if err := writeAudit(ctx, tx, actor, models.AuditDelete, models.AuditEntityProduct, id, before, nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return dbError("delete product", err)
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// Restore brings back a soft-deleted product. It fails with ErrConflict
+// when the product is not deleted, its category is, or another product
+// has taken its SKU or barcode in the meantime.
+func (r *ProductRepository) Restore(ctx context.Context, id int, actor models.Actor) (*models.Product, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, dbError("restore product", err)
+	}
+	defer tx.Rollback()
+
+	var before models.Product
+	err = scanProduct(tx.QueryRowContext(ctx, productSelect+" WHERE p.id = $1 FOR UPDATE OF p", id), &before)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("product %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, dbError("restore product", err)
+	}
+	if before.DeletedAt == nil {
+		return nil, fmt.Errorf("%w: product %d is not deleted", ErrConflict, id)
+	}
+	err = checkCategory(ctx, tx, before.CategoryID)
+	if errors.Is(err, ErrValidation) {
+		return nil, fmt.Errorf("%w: category %d is deleted; restore it first", ErrConflict, *before.CategoryID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE products SET deleted_at = NULL, version = version + 1 WHERE id = $1", id)
+	if err != nil {
+		return nil, dbError("restore product", err)
+	}
+	var p models.Product
+	if err := scanProduct(tx.QueryRowContext(ctx, productSelect+" WHERE p.id = $1", id), &p); err != nil {
+		return nil, dbError("restore product", err)
+	}
+	if err := writeAudit(ctx, tx, actor, models.AuditRestore, models.AuditEntityProduct, id, auditProduct(before), auditProduct(p)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, dbError("restore product", err)
+	}
+	return &p, nil
+}
+
+// GetForUpdate loads a live product inside tx and locks its row until the
+// transaction ends, so concurrent checkouts cannot oversell the same stock.
+func (r *ProductRepository) GetForUpdate(ctx context.Context, tx *sql.Tx, id int) (*models.Product, error) {
+	var p models.Product
+	err := tx.QueryRowContext(ctx, `SELECT id, name, price, stock, COALESCE(sku, ''), COALESCE(barcode, ''), category_id,
+		reorder_level, reorder_qty, version
+		FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, id).
+		Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.SKU, &p.Barcode, &p.CategoryID,
+			&p.ReorderLevel, &p.ReorderQty, &p.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("product %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, dbError("lock product", err)
+	}
+	return &p, nil
+}
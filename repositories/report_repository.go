@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"kasir-api/models"
+	"time"
+)
+
+type ReportRepository struct {
+	db *sql.DB
+}
+
+func NewReportRepository(db *sql.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// GetSalesSummary aggregates sales with created_at in [from, to). The best
+// seller is nil when no sales fall in the range.
+func (r *ReportRepository) GetSalesSummary(ctx context.Context, from, to time.Time) (*models.SalesReport, error) {
+	var report models.SalesReport
+	err := r.db.QueryRowContext(ctx, `SELECT COALESCE(SUM(total_amount), 0), COUNT(*)
+		FROM transactions WHERE created_at >= $1 AND created_at < $2`, from, to).
+		Scan(&report.TotalRevenue, &report.TotalTransactions)
+	if err != nil {
+		return nil, dbError("sum sales", err)
+	}
+
+	var best models.BestSeller
+	err = r.db.QueryRowContext(ctx, `SELECT td.product_id, MAX(td.product_name), SUM(td.quantity) AS qty
+		FROM transaction_details td
+		JOIN transactions t ON t.id = td.transaction_id
+		WHERE t.created_at >= $1 AND t.created_at < $2
+		GROUP BY td.product_id
+		ORDER BY qty DESC, td.product_id
+		LIMIT 1`, from, to).
+		Scan(&best.ProductID, &best.Name, &best.QuantitySold)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &report, nil
+	}
+	if err != nil {
+		return nil, dbError("best seller", err)
+	}
+	report.BestSeller = &best
+	return &report, nil
+}
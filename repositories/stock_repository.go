@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"kasir-api/models"
+)
+
+type StockRepository struct {
+	db *sql.DB
+}
+
+func NewStockRepository(db *sql.DB) *StockRepository {
+	return &StockRepository{db: db}
+}
+
+// recordMovement applies m.Quantity to the product's stock and appends m to
+// the ledger inside tx, filling in its ID, balance and timestamp. The
+// stock + delta >= 0 guard turns an overdraw into ErrInsufficientStock, so
+// products.stock always equals the last balance in the ledger. The
+// product's version is bumped, since its stock is part of what an If-Match
+// on it vouches for.
+func recordMovement(ctx context.Context, tx *sql.Tx, m *models.StockMovement, actor models.Actor) error {
+	err := tx.QueryRowContext(ctx, `UPDATE products SET stock = stock + $1, version = version + 1
+		WHERE id = $2 AND stock + $1 >= 0 RETURNING stock`, m.Quantity, m.ProductID).Scan(&m.Balance)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: product %d", ErrInsufficientStock, m.ProductID)
+	}
+	if err != nil {
+		return dbError("update stock", err)
+	}
+	return insertMovement(ctx, tx, m, actor)
+}
+
+// insertMovement appends m, whose Balance is already set, to the ledger.
+func insertMovement(ctx context.Context, tx *sql.Tx, m *models.StockMovement, actor models.Actor) error {
+	m.Actor = actor.Username
+	if actor.ID != 0 {
+		id := actor.ID
+		m.ActorID = &id
+	}
+	err := tx.QueryRowContext(ctx, `INSERT INTO stock_movements
+		(product_id, kind, quantity, balance, reason, transaction_id, actor_id, actor)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at`,
+		m.ProductID, m.Kind, m.Quantity, m.Balance, m.Reason, m.TransactionID, m.ActorID, m.Actor).
+		Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		return dbError("record stock movement", err)
+	}
+	return nil
+}
+
+// Adjust records a manual stock movement such as a restock or a write-off.
+func (r *StockRepository) Adjust(ctx context.Context, m *models.StockMovement, actor models.Actor) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError("adjust stock", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)", m.ProductID).Scan(&exists)
+	if err != nil {
+		return dbError("adjust stock", err)
+	}
+	if !exists {
+		return fmt.Errorf("product %d: %w", m.ProductID, ErrNotFound)
+	}
+	if err := recordMovement(ctx, tx, m, actor); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return dbError("adjust stock", err)
+	}
+	return nil
+}
+
+var stockMovementSortColumns = map[string]string{
+	"id":         "id",
+	"created_at": "created_at",
+}
+
+// History returns one page of a product's stock movements, newest first
+// unless f.Sort says otherwise, along with the total count. The ledger of a
+// deleted product stays readable.
+func (r *StockRepository) History(ctx context.Context, productID int, f models.StockMovementFilter) ([]models.StockMovement, int, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM products WHERE id = $1)", productID).Scan(&exists)
+	if err != nil {
+		return nil, 0, dbError("stock history", err)
+	}
+	if !exists {
+		return nil, 0, fmt.Errorf("product %d: %w", productID, ErrNotFound)
+	}
+
+	var where whereClause
+	where.add("product_id = $%d", productID)
+	if f.Kind != "" {
+		where.add("kind = $%d", f.Kind)
+	}
+
+	sort := f.Sort
+	if len(sort) == 0 {
+		sort = []models.SortField{{Name: "id", Desc: true}}
+	}
+	order, err := orderBy(sort, stockMovementSortColumns, "id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM stock_movements"+where.String(), where.args...).Scan(&total)
+	if err != nil {
+		return nil, 0, dbError("count stock movements", err)
+	}
+
+	limit, args := where.page(f.ListOptions)
+	rows, err := r.db.QueryContext(ctx, `SELECT id, product_id, kind, quantity, balance, reason,
+		transaction_id, actor_id, actor, created_at
+		FROM stock_movements`+where.String()+order+limit, args...)
+	if err != nil {
+		return nil, 0, dbError("stock history", err)
+	}
+	defer rows.Close()
+
+	var movements []models.StockMovement
+	for rows.Next() {
+		var m models.StockMovement
+		if err := rows.Scan(&m.ID, &m.ProductID, &m.Kind, &m.Quantity, &m.Balance, &m.Reason,
+			&m.TransactionID, &m.ActorID, &m.Actor, &m.CreatedAt); err != nil {
+			return nil, 0, dbError("stock history", err)
+		}
+		movements = append(movements, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, dbError("stock history", err)
+	}
+	return movements, total, nil
+}
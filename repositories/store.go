@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"kasir-api/models"
+	"time"
+)
+
+// ProductStore is the product persistence contract the services depend on.
+// ProductRepository implements it on Postgres and MemoryProductRepository
+// in process memory. Mutations are recorded in the audit log under actor.
+// ctx bounds the database work; the Postgres queries are cancelled with it.
+// Update and Delete take the version the caller last saw and fail with
+// ErrVersionMismatch if the row has moved on; version 0 skips the check.
+// Patch is Update restricted to the named JSON fields, and reloads p with
+// the stored row afterwards. Delete is soft: reads skip deleted rows unless
+// asked to include them, and Restore brings a row back.
+type ProductStore interface {
+	GetAll(ctx context.Context, f models.ProductFilter) ([]models.Product, int, error)
+	GetByID(ctx context.Context, id int, includeDeleted bool) (*models.Product, error)
+	GetByBarcode(ctx context.Context, code string) (*models.Product, error)
+	Search(ctx context.Context, q string, limit int) ([]models.ProductSearchResult, error)
+	Create(ctx context.Context, p *models.Product, actor models.Actor) error
+	Update(ctx context.Context, p *models.Product, actor models.Actor) error
+	Patch(ctx context.Context, p *models.Product, fields []string, actor models.Actor) error
+	Delete(ctx context.Context, id, version int, actor models.Actor) error
+	Restore(ctx context.Context, id int, actor models.Actor) (*models.Product, error)
+}
+
+// CategoryStore is the category persistence contract the services depend on.
+// A cascading Delete soft-deletes the category's products with it, and
+// Restore brings those same products back.
+type CategoryStore interface {
+	GetAll(ctx context.Context, f models.CategoryFilter) ([]models.Category, int, error)
+	GetByID(ctx context.Context, id int, includeDeleted bool) (*models.Category, error)
+	Create(ctx context.Context, c *models.Category, actor models.Actor) error
+	Update(ctx context.Context, c *models.Category, actor models.Actor) error
+	Patch(ctx context.Context, c *models.Category, fields []string, actor models.Actor) error
+	Delete(ctx context.Context, id, version int, cascade bool, actor models.Actor) error
+	Restore(ctx context.Context, id int, actor models.Actor) (*models.Category, error)
+}
+
+// StockStore records stock movements and reads back a product's ledger.
+// Adjust fills in the movement's ID, balance, actor and timestamp.
+type StockStore interface {
+	Adjust(ctx context.Context, m *models.StockMovement, actor models.Actor) error
+	History(ctx context.Context, productID int, f models.StockMovementFilter) ([]models.StockMovement, int, error)
+}
+
+// TransactionStore records checkouts. CreateTransaction sells the whole
+// cart or nothing, and returns an alert for every product the sale took
+// below its reorder level.
+type TransactionStore interface {
+	CreateTransaction(ctx context.Context, items []models.CheckoutItem, actor models.Actor) (*models.Transaction, []models.LowStockAlert, error)
+}
+
+// ReportStore aggregates the sales recorded by a TransactionStore over the
+// half-open range [from, to).
+type ReportStore interface {
+	GetSalesSummary(ctx context.Context, from, to time.Time) (*models.SalesReport, error)
+}
+
+// AuditStore reads back the audit log written by catalogue mutations.
+type AuditStore interface {
+	List(ctx context.Context, f models.AuditFilter) ([]models.AuditEntry, int, error)
+}
+
+// UserStore persists user accounts and their refresh tokens.
+type UserStore interface {
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	GetByID(ctx context.Context, id int) (*models.User, error)
+	Create(ctx context.Context, u *models.User) error
+	CreateRefreshToken(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error
+	ConsumeRefreshToken(ctx context.Context, tokenHash string) (int, error)
+}
+
+var (
+	_ AuditStore    = (*AuditRepository)(nil)
+	_ AuditStore    = (*MemoryAuditRepository)(nil)
+	_ StockStore    = (*StockRepository)(nil)
+	_ StockStore    = (*MemoryStockRepository)(nil)
+	_ UserStore     = (*UserRepository)(nil)
+	_ UserStore     = (*MemoryUserRepository)(nil)
+	_ ProductStore  = (*ProductRepository)(nil)
+	_ CategoryStore = (*CategoryRepository)(nil)
+	_ ProductStore  = (*MemoryProductRepository)(nil)
+	_ CategoryStore = (*MemoryCategoryRepository)(nil)
+
+	_ TransactionStore = (*TransactionRepository)(nil)
+	_ TransactionStore = (*MemoryTransactionRepository)(nil)
+	_ ReportStore      = (*ReportRepository)(nil)
+	_ ReportStore      = (*MemoryReportRepository)(nil)
+)
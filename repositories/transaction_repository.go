@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+)
+
+type TransactionRepository struct {
+	db       *sql.DB
+	products *ProductRepository
+}
+
+func NewTransactionRepository(db *sql.DB, products *ProductRepository) *TransactionRepository {
+	return &TransactionRepository{db: db, products: products}
+}
+
+// CreateTransaction records a sale for the given cart. Stock checks, the
+// transaction rows and a sale movement per line are written in a single
+// sql.Tx, so either the whole cart is sold or nothing changes. It also
+// returns an alert for every product the sale took below its reorder level.
+func (r *TransactionRepository) CreateTransaction(ctx context.Context, items []models.CheckoutItem, actor models.Actor) (*models.Transaction, []models.LowStockAlert, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, dbError("begin checkout", err)
+	}
+	defer tx.Rollback()
+
+	var trx models.Transaction
+	var locked []*models.Product
+	for _, item := range items {
+		p, err := r.products.GetForUpdate(ctx, tx, item.ProductID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if p.Stock < item.Quantity {
+			return nil, nil, fmt.Errorf("%w: %s has %d, want %d", ErrInsufficientStock, p.Name, p.Stock, item.Quantity)
+		}
+		locked = append(locked, p)
+
+		subtotal := p.Price * item.Quantity
+		trx.TotalAmount += subtotal
+		trx.Details = append(trx.Details, models.TransactionDetail{
+			ProductID:   p.ID,
+			ProductName: p.Name,
+			Quantity:    item.Quantity,
+			Price:       p.Price,
+			Subtotal:    subtotal,
+		})
+	}
+
+	err = tx.QueryRowContext(ctx, "INSERT INTO transactions (total_amount) VALUES ($1) RETURNING id, created_at",
+		trx.TotalAmount).Scan(&trx.ID, &trx.CreatedAt)
+	if err != nil {
+		return nil, nil, dbError("insert transaction", err)
+	}
+
+	var alerts []models.LowStockAlert
+	for i := range trx.Details {
+		d := &trx.Details[i]
+		d.TransactionID = trx.ID
+		err = tx.QueryRowContext(ctx, `INSERT INTO transaction_details
+			(transaction_id, product_id, product_name, quantity, price, subtotal)
+			VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+			d.TransactionID, d.ProductID, d.ProductName, d.Quantity, d.Price, d.Subtotal).Scan(&d.ID)
+		if err != nil {
+			return nil, nil, dbError("insert transaction detail", err)
+		}
+
+		m := models.StockMovement{
+			ProductID:     d.ProductID,
+			Kind:          models.StockSale,
+			Quantity:      -d.Quantity,
+			TransactionID: &trx.ID,
+		}
+		if err := recordMovement(ctx, tx, &m, actor); err != nil {
+			return nil, nil, err
+		}
+
+		if alert, ok := saleAlert(locked[i], m, trx.ID); ok {
+			alerts = append(alerts, alert)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, dbError("commit checkout", err)
+	}
+	return &trx, alerts, nil
+}
+
+// saleAlert builds the low-stock alert for sale movement m of product p,
+// which holds the stock as it was before the sale. It alerts only when this
+// sale crossed the threshold, not on every sale of a product that is
+// already low.
+func saleAlert(p *models.Product, m models.StockMovement, transactionID int) (models.LowStockAlert, bool) {
+	if p.LowStock() || m.Balance >= p.ReorderLevel {
+		return models.LowStockAlert{}, false
+	}
+	return models.LowStockAlert{
+		ProductID:     p.ID,
+		ProductName:   p.Name,
+		SKU:           p.SKU,
+		Stock:         m.Balance,
+		ReorderLevel:  p.ReorderLevel,
+		ReorderQty:    p.ReorderQty,
+		TransactionID: transactionID,
+		At:            m.CreatedAt,
+	}, true
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"kasir-api/models"
+	"time"
+)
+
+type UserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	var u models.User
+	err := r.db.QueryRowContext(ctx, "SELECT id, username, password_hash, role, created_at FROM users WHERE username = $1", username).
+		Scan(&u.ID, &u.Username, &u.PasswordHash, &u.Role, &u.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("user %s: %w", username, ErrNotFound)
+	}
+	if err != nil {
+		return nil, dbError("get user", err)
+	}
+	return &u, nil
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
+	var u models.User
+	err := r.db.QueryRowContext(ctx, "SELECT id, username, password_hash, role, created_at FROM users WHERE id = $1", id).
+		Scan(&u.ID, &u.Username, &u.PasswordHash, &u.Role, &u.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("user %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, dbError("get user", err)
+	}
+	return &u, nil
+}
+
+func (r *UserRepository) Create(ctx context.Context, u *models.User) error {
+	err := r.db.QueryRowContext(ctx, "INSERT INTO users (username, password_hash, role) VALUES ($1, $2, $3) RETURNING id, created_at",
+		u.Username, u.PasswordHash, u.Role).Scan(&u.ID, &u.CreatedAt)
+	if err != nil {
+		return dbError("create user", err)
+	}
+	return nil
+}
+
+func (r *UserRepository) CreateRefreshToken(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, "INSERT INTO refresh_tokens (user_id, token_hash, expires_at) VALUES ($1, $2, $3)",
+		userID, tokenHash, expiresAt)
+	if err != nil {
+		return dbError("create refresh token", err)
+	}
+	return nil
+}
+
+// ConsumeRefreshToken revokes a live refresh token and returns its owner.
+// Revoking and checking happen in one statement, so a token can be
+// exchanged at most once even under concurrent requests.
+func (r *UserRepository) ConsumeRefreshToken(ctx context.Context, tokenHash string) (int, error) {
+	var userID int
+	err := r.db.QueryRowContext(ctx, `UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		RETURNING user_id`, tokenHash).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("refresh token: %w", ErrNotFound)
+	}
+	if err != nil {
+		return 0, dbError("consume refresh token", err)
+	}
+	return userID, nil
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"context"
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type AuditService struct {
+	repo repositories.AuditStore
+}
+
+func NewAuditService(repo repositories.AuditStore) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+func (s *AuditService) List(ctx context.Context, f models.AuditFilter) ([]models.AuditEntry, int, error) {
+	if err := validationError(f.Validate()); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.List(ctx, f)
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrUnauthorized is returned for bad credentials and invalid, expired or
+// already used tokens.
+var ErrUnauthorized = errors.New("unauthorized")
+
+const tokenIssuer = "kasir-api"
+
+// dummyHash is compared against when a username does not exist, so failed
+// logins take the same time whether or not the user is real.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("kasir-api-dummy"), bcrypt.DefaultCost)
+
+// Claims are the JWT claims of an access token. The subject is the user ID.
+type Claims struct {
+	Username string      `json:"username"`
+	Role     models.Role `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// Can reports whether the token's role grants p.
+func (c *Claims) Can(p models.Permission) bool {
+	return c.Role.Can(p)
+}
+
+// UserID returns the numeric user ID carried in the subject claim.
+func (c *Claims) UserID() int {
+	id, _ := strconv.Atoi(c.Subject)
+	return id
+}
+
+type AuthConfig struct {
+	Secret     []byte
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+type AuthService struct {
+	users  repositories.UserStore
+	config AuthConfig
+}
+
+func NewAuthService(users repositories.UserStore, config AuthConfig) *AuthService {
+	return &AuthService{users: users, config: config}
+}
+
+// Login checks a username and password and issues a fresh token pair.
+func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest) (*models.TokenPair, error) {
+	fields := make(map[string]string)
+	if req.Username == "" {
+		fields["username"] = "is required"
+	}
+	if req.Password == "" {
+		fields["password"] = "is required"
+	}
+	if err := validationError(fields); err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.GetByUsername(ctx, req.Username)
+	if errors.Is(err, repositories.ErrNotFound) {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(req.Password))
+		slog.WarnContext(ctx, "login failed", "username", req.Username, "reason", "unknown user")
+		return nil, fmt.Errorf("%w: invalid username or password", ErrUnauthorized)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)) != nil {
+		slog.WarnContext(ctx, "login failed", "username", req.Username, "reason", "wrong password")
+		return nil, fmt.Errorf("%w: invalid username or password", ErrUnauthorized)
+	}
+	return s.issue(ctx, user)
+}
+
+// Refresh exchanges a refresh token for a new pair. Refresh tokens are
+// single use: the presented token is revoked as part of the exchange.
+func (s *AuthService) Refresh(ctx context.Context, req *models.RefreshRequest) (*models.TokenPair, error) {
+	if req.RefreshToken == "" {
+		return nil, &ValidationError{Fields: map[string]string{"refresh_token": "is required"}}
+	}
+
+	userID, err := s.users.ConsumeRefreshToken(ctx, hashToken(req.RefreshToken))
+	if errors.Is(err, repositories.ErrNotFound) {
+		slog.WarnContext(ctx, "refresh token rejected")
+		return nil, fmt.Errorf("%w: invalid or expired refresh token", ErrUnauthorized)
+	}
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(ctx, user)
+}
+
+// Logout revokes a refresh token. Unknown tokens are ignored so logging out
+// twice is harmless.
+func (s *AuthService) Logout(ctx context.Context, req *models.RefreshRequest) error {
+	_, err := s.users.ConsumeRefreshToken(ctx, hashToken(req.RefreshToken))
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// Authenticate verifies a signed access token and returns its claims.
+func (s *AuthService) Authenticate(token string) (*Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return s.config.Secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(tokenIssuer), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+	return &claims, nil
+}
+
+// CreateUser registers a new account with the given role.
+func (s *AuthService) CreateUser(ctx context.Context, req *models.CreateUserRequest) (*models.User, error) {
+	fields := make(map[string]string)
+	if len(req.Username) < 3 || len(req.Username) > 64 {
+		fields["username"] = "must be 3-64 characters"
+	}
+	if len(req.Password) < 8 {
+		fields["password"] = "must be at least 8 characters"
+	} else if len(req.Password) > 72 {
+		// bcrypt ignores everything past 72 bytes.
+		fields["password"] = "must be at most 72 characters"
+	}
+	if !req.Role.Valid() {
+		fields["role"] = "must be cashier, supervisor or admin"
+	}
+	if err := validationError(fields); err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	user := &models.User{Username: req.Username, PasswordHash: string(hash), Role: req.Role}
+	if err := s.users.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "user created", "user_id", user.ID, "username", user.Username, "role", user.Role)
+	return user, nil
+}
+
+// EnsureAdmin creates an admin account unless the username is already
+// taken. It is used to bootstrap the first account.
+func (s *AuthService) EnsureAdmin(ctx context.Context, username, password string) error {
+	_, err := s.users.GetByUsername(ctx, username)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, repositories.ErrNotFound) {
+		return err
+	}
+
+	_, err = s.CreateUser(ctx, &models.CreateUserRequest{Username: username, Password: password, Role: models.RoleAdmin})
+	return err
+}
+
+func (s *AuthService) issue(ctx context.Context, user *models.User) (*models.TokenPair, error) {
+	now := time.Now()
+	claims := Claims{
+		Username: user.Username,
+		Role:     user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokenIssuer,
+			Subject:   strconv.Itoa(user.ID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.config.AccessTTL)),
+		},
+	}
+	access, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.config.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	refresh := base64.RawURLEncoding.EncodeToString(b)
+	if err := s.users.CreateRefreshToken(ctx, user.ID, hashToken(refresh), now.Add(s.config.RefreshTTL)); err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.config.AccessTTL.Seconds()),
+	}, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+	"testing"
+	"time"
+)
+
+var testAuthConfig = AuthConfig{
+	Secret:     []byte("0123456789abcdef0123456789abcdef"),
+	AccessTTL:  time.Minute,
+	RefreshTTL: time.Hour,
+}
+
+// newTestAuth returns an auth service over an in-memory store holding the
+// user "kasir" with password "password1" and the given role.
+func newTestAuth(t *testing.T, config AuthConfig, role models.Role) *AuthService {
+	t.Helper()
+	auth := NewAuthService(newTestStore(t).Users(), config)
+	req := &models.CreateUserRequest{Username: "kasir", Password: "password1", Role: role}
+	if _, err := auth.CreateUser(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	return auth
+}
+
+func TestAuthServiceLogin(t *testing.T) {
+	ctx := context.Background()
+	auth := newTestAuth(t, testAuthConfig, models.RoleCashier)
+
+	pair, err := auth.Login(ctx, &models.LoginRequest{Username: "kasir", Password: "password1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := auth.Authenticate(pair.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Username != "kasir" || claims.Role != models.RoleCashier || claims.UserID() == 0 {
+		t.Errorf("claims = %+v, want cashier kasir with a user ID", claims)
+	}
+
+	for _, req := range []models.LoginRequest{
+		{Username: "kasir", Password: "password2"},
+		{Username: "nobody", Password: "password1"},
+	} {
+		if _, err := auth.Login(ctx, &req); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("Login(%s, %s) = %v, want ErrUnauthorized", req.Username, req.Password, err)
+		}
+	}
+
+	_, err = auth.Login(ctx, &models.LoginRequest{})
+	wantFieldError(t, err, "username", "is required")
+}
+
+func TestAuthServiceRefreshIsSingleUse(t *testing.T) {
+	ctx := context.Background()
+	auth := newTestAuth(t, testAuthConfig, models.RoleCashier)
+
+	pair, err := auth.Login(ctx, &models.LoginRequest{Username: "kasir", Password: "password1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := auth.Refresh(ctx, &models.RefreshRequest{RefreshToken: pair.RefreshToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.RefreshToken == pair.RefreshToken {
+		t.Error("Refresh returned the same refresh token")
+	}
+	if _, err := auth.Refresh(ctx, &models.RefreshRequest{RefreshToken: pair.RefreshToken}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("reusing a refresh token = %v, want ErrUnauthorized", err)
+	}
+
+	if err := auth.Logout(ctx, &models.RefreshRequest{RefreshToken: next.RefreshToken}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.Refresh(ctx, &models.RefreshRequest{RefreshToken: next.RefreshToken}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("refreshing after logout = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestAuthServiceAuthenticateRejectsBadTokens(t *testing.T) {
+	ctx := context.Background()
+	auth := newTestAuth(t, testAuthConfig, models.RoleAdmin)
+
+	other := testAuthConfig
+	other.Secret = []byte("fedcba9876543210fedcba9876543210")
+	forged, err := newTestAuth(t, other, models.RoleAdmin).
+		Login(ctx, &models.LoginRequest{Username: "kasir", Password: "password1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expiredConfig := testAuthConfig
+	expiredConfig.AccessTTL = -time.Minute
+	expired, err := newTestAuth(t, expiredConfig, models.RoleAdmin).
+		Login(ctx, &models.LoginRequest{Username: "kasir", Password: "password1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{
+		"foreign secret": forged.AccessToken,
+		"expired":        expired.AccessToken,
+		"garbage":        "not.a.jwt",
+	} {
+		if _, err := auth.Authenticate(token); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("%s token: Authenticate = %v, want ErrUnauthorized", name, err)
+		}
+	}
+}
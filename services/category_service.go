@@ -1,34 +1,105 @@
 package services
 
 import (
+	"context"
+	"fmt"
 	"kasir-api/models"
 	"kasir-api/repositories"
 )
 
 type CategoryService struct {
-	repo *repositories.CategoryRepository
+	repo        repositories.CategoryStore
+	productRepo repositories.ProductStore
 }
 
-func NewCategoryService(repo *repositories.CategoryRepository) *CategoryService {
-	return &CategoryService{repo: repo}
+func NewCategoryService(repo repositories.CategoryStore, productRepo repositories.ProductStore) *CategoryService {
+	return &CategoryService{repo: repo, productRepo: productRepo}
 }
 
-func (s *CategoryService) GetAll() ([]models.Category, error) {
-	return s.repo.GetAll()
+func (s *CategoryService) GetAll(ctx context.Context, f models.CategoryFilter) ([]models.Category, int, error) {
+	if err := validationError(f.Validate()); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.GetAll(ctx, f)
 }
 
-func (s *CategoryService) GetByID(id int) (*models.Category, error) {
-	return s.repo.GetByID(id)
+// GetByID loads a category; a deleted one is only found with
+// includeDeleted.
+func (s *CategoryService) GetByID(ctx context.Context, id int, includeDeleted bool) (*models.Category, error) {
+	return s.repo.GetByID(ctx, id, includeDeleted)
 }
 
-func (s *CategoryService) Create(c *models.Category) error {
-	return s.repo.Create(c)
+// GetProducts lists the products in a category, failing if the category
+// itself does not exist rather than returning an empty list.
+func (s *CategoryService) GetProducts(ctx context.Context, id int, f models.ProductFilter) ([]models.Product, int, error) {
+	f.CategoryID = &id
+	if err := validationError(f.Validate()); err != nil {
+		return nil, 0, err
+	}
+	if _, err := s.repo.GetByID(ctx, id, false); err != nil {
+		return nil, 0, err
+	}
+	return s.productRepo.GetAll(ctx, f)
 }
 
-func (s *CategoryService) Update(c *models.Category) error {
-	return s.repo.Update(c)
+func (s *CategoryService) Create(ctx context.Context, c *models.Category, actor models.Actor) error {
+	c.ClearReadOnly()
+	if err := validationError(c.Validate()); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, c, actor)
 }
 
-func (s *CategoryService) Delete(id int) error {
-	return s.repo.Delete(id)
+func (s *CategoryService) Update(ctx context.Context, c *models.Category, actor models.Actor) error {
+	c.ClearReadOnly()
+	if err := validationError(c.Validate()); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, c, actor)
+}
+
+// Patch applies a JSON Merge Patch (RFC 7396) to a category at version (0
+// for any), validating the merged category and writing only the patched
+// fields.
+func (s *CategoryService) Patch(ctx context.Context, id, version int, body []byte, actor models.Actor) (*models.Category, error) {
+	patch, err := parseMergePatch(body, models.CategoryPatchFields, nil, models.CategoryReadOnlyFields)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.repo.GetByID(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != current.Version {
+		return nil, fmt.Errorf("category %d: %w", id, repositories.ErrVersionMismatch)
+	}
+	if len(patch.fields) == 0 {
+		return current, nil
+	}
+
+	var c models.Category
+	if err := patch.apply(current, &c); err != nil {
+		return nil, err
+	}
+	if err := validationError(c.Validate()); err != nil {
+		return nil, err
+	}
+	c.ID, c.Version = id, version
+	if err := s.repo.Patch(ctx, &c, patch.fields, actor); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Delete soft-deletes a category if it is still at version; 0 skips the
+// check. Without cascade it fails with repositories.ErrCategoryInUse while
+// live products reference the category.
+func (s *CategoryService) Delete(ctx context.Context, id, version int, cascade bool, actor models.Actor) error {
+	return s.repo.Delete(ctx, id, version, cascade, actor)
+}
+
+// Restore undoes Delete, bringing back the products a cascading delete
+// took with the category.
+func (s *CategoryService) Restore(ctx context.Context, id int, actor models.Actor) (*models.Category, error) {
+	return s.repo.Restore(ctx, id, actor)
 }
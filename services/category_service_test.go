@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"testing"
+	"time"
+)
+
+func TestCategoryServiceUpdateIgnoresDeletedAt(t *testing.T) {
+	ctx := context.Background()
+	_, categories := newTestServices(t)
+	deletedAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c := &models.Category{ID: 1, Name: "Makanan ringan", DeletedAt: &deletedAt}
+	if err := categories.Update(ctx, c, testActor); err != nil {
+		t.Fatal(err)
+	}
+	if c.DeletedAt != nil {
+		t.Errorf("Update returned deleted_at %v", c.DeletedAt)
+	}
+	if _, err := categories.GetByID(ctx, 1, false); err != nil {
+		t.Errorf("category after a body with deleted_at: %v", err)
+	}
+}
+
+func TestCategoryServicePatchNull(t *testing.T) {
+	_, categories := newTestServices(t)
+	_, err := categories.Patch(context.Background(), 1, 0, []byte(`{"description":null}`), testActor)
+	wantFieldError(t, err, "description", "must not be null")
+}
+
+func TestCategoryServiceDeleteAndRestore(t *testing.T) {
+	ctx := context.Background()
+	products, categories := newTestServices(t)
+
+	if err := categories.Delete(ctx, 1, 0, false, testActor); !errors.Is(err, repositories.ErrCategoryInUse) {
+		t.Fatalf("Delete of a category in use = %v, want ErrCategoryInUse", err)
+	}
+
+	// Kecap Bango is deleted on its own first, so restoring the category
+	// must leave it deleted.
+	if err := products.Delete(ctx, 3, 0, testActor); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	if err := categories.Delete(ctx, 1, 0, true, testActor); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := categories.GetByID(ctx, 1, false); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("GetByID of a deleted category = %v, want ErrNotFound", err)
+	}
+	if _, err := products.GetByID(ctx, 1, false); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("product of a cascade-deleted category = %v, want ErrNotFound", err)
+	}
+	page := models.ListOptions{Page: 1, Limit: 10}
+	if _, total, _ := categories.GetAll(ctx, models.CategoryFilter{ListOptions: page}); total != 1 {
+		t.Errorf("GetAll total = %d, want 1 live category", total)
+	}
+	if _, err := products.Restore(ctx, 1, testActor); !errors.Is(err, repositories.ErrConflict) {
+		t.Errorf("restoring a product of a deleted category = %v, want ErrConflict", err)
+	}
+
+	c, err := categories.Restore(ctx, 1, testActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.DeletedAt != nil {
+		t.Errorf("Restore returned deleted_at %v", c.DeletedAt)
+	}
+	if _, err := products.GetByID(ctx, 1, false); err != nil {
+		t.Errorf("cascade-deleted product after category restore: %v", err)
+	}
+	if _, err := products.GetByID(ctx, 3, false); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("separately deleted product after category restore = %v, want ErrNotFound", err)
+	}
+	if _, err := categories.Restore(ctx, 1, testActor); !errors.Is(err, repositories.ErrConflict) {
+		t.Errorf("restoring a live category = %v, want ErrConflict", err)
+	}
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"errors"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"testing"
+)
+
+var testActor = models.Actor{ID: 1, Username: "tester"}
+
+// newTestStore returns an in-memory store holding the demo catalogue:
+// categories 1 Makanan and 2 Minuman, and products 1 Indomie Goreng (3500,
+// stock 100, barcode 0089686010947), 2 Teh Botol (3000, stock 50, barcode
+// 8886008101053) and 3 Kecap Bango (12000, stock 20, reorder level 24).
+func newTestStore(t *testing.T) *repositories.MemoryStore {
+	t.Helper()
+	store := repositories.NewMemoryStore()
+	store.SeedDemo()
+	return store
+}
+
+func wantFieldError(t *testing.T, err error, field, msg string) {
+	t.Helper()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("error = %v, want a ValidationError", err)
+	}
+	if got := verr.Fields[field]; got != msg {
+		t.Errorf("error for %s = %q, want %q (all: %v)", field, got, msg, verr.Fields)
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"kasir-api/metrics"
+	"kasir-api/models"
+	"kasir-api/notify"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// lowStockQueueSize bounds the alerts waiting for delivery. A checkout never
+// waits on the notifier; when the queue is full the alert is dropped.
+const lowStockQueueSize = 100
+
+type queuedAlert struct {
+	ctx   context.Context
+	alert models.LowStockAlert
+}
+
+// LowStockChecker delivers low-stock alerts through a notifier in the
+// background, so a slow webhook cannot hold up the till.
+type LowStockChecker struct {
+	notifier notify.Notifier
+	timeout  time.Duration
+	queue    chan queuedAlert
+	done     chan struct{}
+
+	// mu guards stopped, so Enqueue never sends on the queue after Stop
+	// has closed it.
+	mu      sync.Mutex
+	stopped bool
+}
+
+// NewLowStockChecker returns a checker sending through notifier, giving
+// each delivery up to timeout. Call Start before the first checkout.
+func NewLowStockChecker(notifier notify.Notifier, timeout time.Duration) *LowStockChecker {
+	return &LowStockChecker{
+		notifier: notifier,
+		timeout:  timeout,
+		queue:    make(chan queuedAlert, lowStockQueueSize),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start runs the delivery loop until Stop is called.
+func (c *LowStockChecker) Start() {
+	go func() {
+		defer close(c.done)
+		for q := range c.queue {
+			c.deliver(q)
+		}
+	}()
+}
+
+// Stop stops accepting alerts and waits until the queued ones are
+// delivered or ctx ends. Checkouts still running afterwards have their
+// alerts dropped.
+func (c *LowStockChecker) Stop(ctx context.Context) {
+	c.mu.Lock()
+	if !c.stopped {
+		c.stopped = true
+		close(c.queue)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+		slog.Warn("low stock alerts left undelivered at shutdown", "queued", len(c.queue))
+	}
+}
+
+// Enqueue hands alerts to the delivery loop without blocking. ctx is the
+// checkout's context; only its values, such as the request ID, are kept.
+func (c *LowStockChecker) Enqueue(ctx context.Context, alerts ...models.LowStockAlert) {
+	ctx = context.WithoutCancel(ctx)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, alert := range alerts {
+		if c.stopped {
+			metrics.LowStockAlerts.WithLabelValues("dropped").Inc()
+			slog.WarnContext(ctx, "low stock alert dropped, shutting down", "product_id", alert.ProductID)
+			continue
+		}
+		select {
+		case c.queue <- queuedAlert{ctx: ctx, alert: alert}:
+		default:
+			metrics.LowStockAlerts.WithLabelValues("dropped").Inc()
+			slog.WarnContext(ctx, "low stock alert dropped, queue full", "product_id", alert.ProductID)
+		}
+	}
+}
+
+func (c *LowStockChecker) deliver(q queuedAlert) {
+	ctx, cancel := context.WithTimeout(q.ctx, c.timeout)
+	defer cancel()
+	if err := c.notifier.Notify(ctx, q.alert); err != nil {
+		metrics.LowStockAlerts.WithLabelValues("failed").Inc()
+		slog.ErrorContext(ctx, "low stock alert failed", "product_id", q.alert.ProductID, "err", err)
+		return
+	}
+	metrics.LowStockAlerts.WithLabelValues("sent").Inc()
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"kasir-api/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingNotifier keeps every alert it is asked to send.
+type recordingNotifier struct {
+	mu     sync.Mutex
+	alerts []models.LowStockAlert
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, alert models.LowStockAlert) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
+func (n *recordingNotifier) sent() []models.LowStockAlert {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]models.LowStockAlert(nil), n.alerts...)
+}
+
+func TestCheckoutAlertsWhenCrossingReorderLevel(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	products := store.Products()
+	notifier := &recordingNotifier{}
+	lowStock := NewLowStockChecker(notifier, time.Second)
+	lowStock.Start()
+	checkout := NewTransactionService(store.Transactions(), products, lowStock)
+
+	p := &models.Product{Name: "Gula Pasir", Price: 15000, Stock: 30, SKU: "MKN-003", ReorderLevel: 24, ReorderQty: 12}
+	if err := products.Create(ctx, p, testActor); err != nil {
+		t.Fatal(err)
+	}
+	// Down to 25, across the level to 23, then further below it. Kecap
+	// Bango starts below its level, so selling it never alerts.
+	for _, qty := range []int{5, 2, 1} {
+		req := &models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: p.ID, Quantity: qty}, {ProductID: 3, Quantity: 1}}}
+		if _, err := checkout.Checkout(ctx, req, testActor); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lowStock.Stop(ctx)
+
+	alerts := notifier.sent()
+	if len(alerts) != 1 {
+		t.Fatalf("alerts = %+v, want one for the sale crossing the level", alerts)
+	}
+	a := alerts[0]
+	if a.ProductID != p.ID || a.Stock != 23 || a.ReorderLevel != 24 || a.ReorderQty != 12 || a.TransactionID == 0 {
+		t.Errorf("alert = %+v, want product %d at 23 of 24, reorder 12", a, p.ID)
+	}
+}
+
+func TestLowStockCheckerEnqueueAfterStop(t *testing.T) {
+	notifier := &recordingNotifier{}
+	lowStock := NewLowStockChecker(notifier, time.Second)
+	lowStock.Start()
+	lowStock.Stop(context.Background())
+	lowStock.Stop(context.Background())
+
+	lowStock.Enqueue(context.Background(), models.LowStockAlert{ProductID: 1})
+	if alerts := notifier.sent(); len(alerts) != 0 {
+		t.Errorf("alerts sent after Stop: %+v", alerts)
+	}
+}
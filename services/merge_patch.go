@@ -0,0 +1,114 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"sort"
+)
+
+// mergePatch is a parsed JSON Merge Patch (RFC 7396) for one entity.
+type mergePatch struct {
+	doc    map[string]any
+	fields []string
+}
+
+// parseMergePatch checks that body is a JSON object whose top-level keys are
+// all in writable, and that only the nullable ones are set to null. The
+// keys, sorted, are the fields the patch changes.
+func parseMergePatch(body []byte, writable, nullable, readOnly []string) (*mergePatch, error) {
+	var doc map[string]any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil || doc == nil {
+		return nil, &ValidationError{Fields: map[string]string{"body": "must be a JSON object"}}
+	}
+
+	p := &mergePatch{doc: doc}
+	fields := make(map[string]string)
+	for key, value := range doc {
+		switch {
+		case slices.Contains(writable, key):
+			if value == nil && !slices.Contains(nullable, key) {
+				fields[key] = "must not be null"
+				continue
+			}
+			p.fields = append(p.fields, key)
+		case slices.Contains(readOnly, key):
+			fields[key] = "is read-only"
+		default:
+			fields[key] = "is not a known field"
+		}
+	}
+	if err := validationError(fields); err != nil {
+		return nil, err
+	}
+	sort.Strings(p.fields)
+	return p, nil
+}
+
+// apply merges the patch into the JSON form of current and decodes the
+// result into dst. Members set to null are removed, so they decode as the
+// field's zero value.
+func (p *mergePatch) apply(current, dst any) error {
+	b, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	var target any
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&target); err != nil {
+		return err
+	}
+
+	merged, err := json.Marshal(mergeValue(target, p.doc))
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(merged, dst)
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return &ValidationError{Fields: map[string]string{typeErr.Field: "must be " + jsonType(typeErr.Type)}}
+	}
+	return err
+}
+
+// jsonType describes the JSON value a Go type decodes from.
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	default:
+		return "a " + t.Kind().String()
+	}
+}
+
+// mergeValue is the MergePatch function of RFC 7396, section 2.
+func mergeValue(target, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = make(map[string]any)
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+		} else {
+			targetObj[key] = mergeValue(targetObj[key], value)
+		}
+	}
+	return targetObj
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// TestMergeValue runs the examples of RFC 7396, appendix A.
+func TestMergeValue(t *testing.T) {
+	tests := []struct {
+		target, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		got := mergeValue(decodeJSON(t, tt.target), decodeJSON(t, tt.patch))
+		if want := decodeJSON(t, tt.want); !reflect.DeepEqual(got, want) {
+			t.Errorf("merge %s into %s = %v, want %v", tt.patch, tt.target, got, want)
+		}
+	}
+}
+
+func decodeJSON(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("decode %s: %v", s, err)
+	}
+	return v
+}
+
+func TestParseMergePatch(t *testing.T) {
+	writable := []string{"name", "price", "sku"}
+	nullable := []string{"sku"}
+	readOnly := []string{"id", "version"}
+
+	tests := []struct {
+		name   string
+		body   string
+		fields []string
+		errors map[string]string
+	}{
+		{"empty patch", `{}`, nil, nil},
+		{"fields sorted", `{"sku":"A-1","name":"Teh"}`, []string{"name", "sku"}, nil},
+		{"nullable field cleared", `{"sku":null}`, []string{"sku"}, nil},
+		{"null on a required field", `{"price":null}`, nil, map[string]string{"price": "must not be null"}},
+		{"read-only field", `{"id":7}`, nil, map[string]string{"id": "is read-only"}},
+		{"unknown field", `{"colour":"red"}`, nil, map[string]string{"colour": "is not a known field"}},
+		{"every problem reported", `{"version":2,"name":null,"x":1}`, nil,
+			map[string]string{"version": "is read-only", "name": "must not be null", "x": "is not a known field"}},
+		{"not an object", `[1,2]`, nil, map[string]string{"body": "must be a JSON object"}},
+		{"null document", `null`, nil, map[string]string{"body": "must be a JSON object"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parseMergePatch([]byte(tt.body), writable, nullable, readOnly)
+			if tt.errors != nil {
+				var verr *ValidationError
+				if !errors.As(err, &verr) {
+					t.Fatalf("parseMergePatch() error = %v, want a ValidationError", err)
+				}
+				if !reflect.DeepEqual(verr.Fields, tt.errors) {
+					t.Errorf("parseMergePatch() errors = %v, want %v", verr.Fields, tt.errors)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseMergePatch() error = %v", err)
+			}
+			if !slices.Equal(p.fields, tt.fields) {
+				t.Errorf("parseMergePatch() fields = %v, want %v", p.fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestMergePatchApply(t *testing.T) {
+	type item struct {
+		Name  string  `json:"name"`
+		Price int     `json:"price"`
+		SKU   *string `json:"sku"`
+	}
+	sku := "A-1"
+	current := item{Name: "Teh", Price: 3000, SKU: &sku}
+
+	p, err := parseMergePatch([]byte(`{"price":3500,"sku":null}`), []string{"name", "price", "sku"}, []string{"sku"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got item
+	if err := p.apply(current, &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := (item{Name: "Teh", Price: 3500}); !reflect.DeepEqual(got, want) {
+		t.Errorf("apply() = %+v, want %+v", got, want)
+	}
+
+	p, err = parseMergePatch([]byte(`{"price":"cheap"}`), []string{"price"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var verr *ValidationError
+	if err := p.apply(current, &got); !errors.As(err, &verr) || verr.Fields["price"] != "must be an integer" {
+		t.Errorf("apply() with a string price = %v, want price: must be an integer", err)
+	}
+}
@@ -1,34 +1,128 @@
 package services
 
 import (
+	"context"
+	"fmt"
 	"kasir-api/models"
 	"kasir-api/repositories"
+	"strings"
 )
 
 type ProductService struct {
-	repo *repositories.ProductRepository
+	repo repositories.ProductStore
 }
 
-func NewProductService(repo *repositories.ProductRepository) *ProductService {
+func NewProductService(repo repositories.ProductStore) *ProductService {
 	return &ProductService{repo: repo}
 }
 
-func (s *ProductService) GetAll() ([]models.Product, error) {
-	return s.repo.GetAll()
+// GetAll returns one page of products matching f and the total match count.
+func (s *ProductService) GetAll(ctx context.Context, f models.ProductFilter) ([]models.Product, int, error) {
+	if err := validationError(f.Validate()); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.GetAll(ctx, f)
 }
 
-func (s *ProductService) GetByID(id int) (*models.Product, error) {
-	return s.repo.GetByID(id)
+// LowStock lists products below their reorder level, emptiest first unless
+// f.Sort says otherwise.
+func (s *ProductService) LowStock(ctx context.Context, f models.ProductFilter) ([]models.Product, int, error) {
+	f.LowStock = true
+	if len(f.Sort) == 0 {
+		f.Sort = []models.SortField{{Name: "stock"}}
+	}
+	return s.GetAll(ctx, f)
 }
 
-func (s *ProductService) Create(p *models.Product) error {
-	return s.repo.Create(p)
+// Search ranks products by how well their name matches q.
+func (s *ProductService) Search(ctx context.Context, q string, limit int) ([]models.ProductSearchResult, error) {
+	q = strings.TrimSpace(q)
+	fields := make(map[string]string)
+	if q == "" {
+		fields["q"] = "is required"
+	} else if len(q) > 100 {
+		fields["q"] = "must be at most 100 characters"
+	}
+	if limit < 1 || limit > models.MaxPageLimit {
+		fields["limit"] = fmt.Sprintf("must be between 1 and %d", models.MaxPageLimit)
+	}
+	if err := validationError(fields); err != nil {
+		return nil, err
+	}
+	return s.repo.Search(ctx, q, limit)
 }
 
-func (s *ProductService) Update(p *models.Product) error {
-	return s.repo.Update(p)
+// GetByID loads a product; a deleted one is only found with includeDeleted.
+func (s *ProductService) GetByID(ctx context.Context, id int, includeDeleted bool) (*models.Product, error) {
+	return s.repo.GetByID(ctx, id, includeDeleted)
 }
 
-func (s *ProductService) Delete(id int) error {
-	return s.repo.Delete(id)
-}
\ No newline at end of file
+// GetByBarcode finds the product for a scanned EAN-13 or UPC-A code.
+func (s *ProductService) GetByBarcode(ctx context.Context, code string) (*models.Product, error) {
+	normalized, ok := models.NormalizeBarcode(code)
+	if !ok {
+		return nil, &ValidationError{Fields: map[string]string{"barcode": "must be a valid EAN-13 or UPC-A code"}}
+	}
+	return s.repo.GetByBarcode(ctx, normalized)
+}
+
+func (s *ProductService) Create(ctx context.Context, p *models.Product, actor models.Actor) error {
+	p.ClearReadOnly()
+	if err := validationError(p.Validate()); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, p, actor)
+}
+
+func (s *ProductService) Update(ctx context.Context, p *models.Product, actor models.Actor) error {
+	p.ClearReadOnly()
+	if err := validationError(p.Validate()); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, p, actor)
+}
+
+// Patch applies a JSON Merge Patch (RFC 7396) to a product at version (0
+// for any). The merged product is validated as a whole, but only the
+// fields named in the patch are written.
+func (s *ProductService) Patch(ctx context.Context, id, version int, body []byte, actor models.Actor) (*models.Product, error) {
+	patch, err := parseMergePatch(body, models.ProductPatchFields, models.ProductNullableFields, models.ProductReadOnlyFields)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.repo.GetByID(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != current.Version {
+		return nil, fmt.Errorf("product %d: %w", id, repositories.ErrVersionMismatch)
+	}
+	if len(patch.fields) == 0 {
+		return current, nil
+	}
+
+	var p models.Product
+	if err := patch.apply(current, &p); err != nil {
+		return nil, err
+	}
+	if err := validationError(p.Validate()); err != nil {
+		return nil, err
+	}
+	p.ID, p.Version = id, version
+	if err := s.repo.Patch(ctx, &p, patch.fields, actor); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Delete soft-deletes a product if it is still at version; 0 skips the
+// check.
+func (s *ProductService) Delete(ctx context.Context, id, version int, actor models.Actor) error {
+	return s.repo.Delete(ctx, id, version, actor)
+}
+
+// Restore undoes Delete. A product whose category is deleted cannot be
+// restored until the category is.
+func (s *ProductService) Restore(ctx context.Context, id int, actor models.Actor) (*models.Product, error) {
+	return s.repo.Restore(ctx, id, actor)
+}
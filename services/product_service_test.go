@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"testing"
+	"time"
+)
+
+// newTestServices returns product and category services over the demo
+// catalogue of newTestStore.
+func newTestServices(t *testing.T) (*ProductService, *CategoryService) {
+	t.Helper()
+	store := newTestStore(t)
+	products := store.Products()
+	return NewProductService(products), NewCategoryService(store.Categories(), products)
+}
+
+func TestProductServiceGetAllRejectsHugePage(t *testing.T) {
+	products, _ := newTestServices(t)
+	f := models.ProductFilter{ListOptions: models.ListOptions{Page: 922337203685477582, Limit: 10}}
+	_, _, err := products.GetAll(context.Background(), f)
+	wantFieldError(t, err, "page", "must be between 1 and 10000")
+}
+
+func TestProductServiceIgnoresReadOnlyFields(t *testing.T) {
+	ctx := context.Background()
+	products, _ := newTestServices(t)
+	deletedAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	food := 1
+
+	created := &models.Product{Name: "Sarden", Price: 9000, CategoryID: &food,
+		CategoryName: "hacked", DeletedAt: &deletedAt}
+	if err := products.Create(ctx, created, testActor); err != nil {
+		t.Fatal(err)
+	}
+	if created.CategoryName != "Makanan" || created.DeletedAt != nil {
+		t.Errorf("Create returned category_name %q, deleted_at %v", created.CategoryName, created.DeletedAt)
+	}
+
+	updated := &models.Product{ID: 1, Name: "Indomie Goreng", Price: 3500, Stock: 100, CategoryID: &food,
+		CategoryName: "hacked", DeletedAt: &deletedAt}
+	if err := products.Update(ctx, updated, testActor); err != nil {
+		t.Fatal(err)
+	}
+	if updated.CategoryName != "Makanan" || updated.DeletedAt != nil {
+		t.Errorf("Update returned category_name %q, deleted_at %v", updated.CategoryName, updated.DeletedAt)
+	}
+
+	for _, id := range []int{1, created.ID} {
+		p, err := products.GetByID(ctx, id, false)
+		if err != nil {
+			t.Fatalf("product %d after a body with deleted_at: %v", id, err)
+		}
+		if p.CategoryName != "Makanan" {
+			t.Errorf("product %d category_name = %q, want Makanan", id, p.CategoryName)
+		}
+	}
+}
+
+func TestProductServicePatchVersionMismatch(t *testing.T) {
+	products, _ := newTestServices(t)
+	_, err := products.Patch(context.Background(), 1, 5, []byte(`{"price":4000}`), testActor)
+	if !errors.Is(err, repositories.ErrVersionMismatch) {
+		t.Errorf("Patch at a stale version = %v, want ErrVersionMismatch", err)
+	}
+}
+
+func TestProductServicePatchNull(t *testing.T) {
+	ctx := context.Background()
+	products, _ := newTestServices(t)
+
+	for _, field := range []string{"name", "price", "stock", "reorder_level", "reorder_qty"} {
+		_, err := products.Patch(ctx, 1, 0, []byte(`{"`+field+`":null}`), testActor)
+		wantFieldError(t, err, field, "must not be null")
+	}
+	p, err := products.GetByID(ctx, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Price != 3500 || p.Stock != 100 || p.Version != 1 {
+		t.Errorf("rejected patches changed the product: %+v", p)
+	}
+
+	p, err = products.Patch(ctx, 1, p.Version, []byte(`{"sku":null,"barcode":null,"category_id":null}`), testActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.SKU != "" || p.Barcode != "" || p.CategoryID != nil || p.CategoryName != "" {
+		t.Errorf("null did not clear the nullable fields: %+v", p)
+	}
+	if p.Price != 3500 || p.Version != 2 {
+		t.Errorf("patch touched other fields: %+v", p)
+	}
+}
+
+func TestProductServiceDeleteAndRestore(t *testing.T) {
+	ctx := context.Background()
+	products, _ := newTestServices(t)
+
+	if err := products.Delete(ctx, 2, 1, testActor); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := products.GetByID(ctx, 2, false); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("GetByID of a deleted product = %v, want ErrNotFound", err)
+	}
+	p, err := products.GetByID(ctx, 2, true)
+	if err != nil || p.DeletedAt == nil {
+		t.Fatalf("GetByID with includeDeleted = %+v, %v; want the deleted product", p, err)
+	}
+	if err := products.Delete(ctx, 2, 0, testActor); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("deleting twice = %v, want ErrNotFound", err)
+	}
+
+	page := models.ListOptions{Page: 1, Limit: 10}
+	if _, total, _ := products.GetAll(ctx, models.ProductFilter{ListOptions: page}); total != 2 {
+		t.Errorf("GetAll total = %d, want 2 live products", total)
+	}
+	if _, total, _ := products.GetAll(ctx, models.ProductFilter{ListOptions: page, IncludeDeleted: true}); total != 3 {
+		t.Errorf("GetAll with IncludeDeleted total = %d, want 3", total)
+	}
+
+	// The deleted product's SKU is free for a new product, which then
+	// blocks the restore.
+	drink := 2
+	reuse := &models.Product{Name: "Teh Kotak", Price: 4000, SKU: "MNM-001", CategoryID: &drink}
+	if err := products.Create(ctx, reuse, testActor); err != nil {
+		t.Fatalf("reusing a deleted product's SKU: %v", err)
+	}
+	if _, err := products.Restore(ctx, 2, testActor); !errors.Is(err, repositories.ErrConflict) {
+		t.Errorf("Restore with its SKU taken = %v, want ErrConflict", err)
+	}
+	if err := products.Delete(ctx, reuse.ID, 0, testActor); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err = products.Restore(ctx, 2, testActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.DeletedAt != nil || p.CategoryName != "Minuman" {
+		t.Errorf("Restore returned %+v", p)
+	}
+	if _, err := products.Restore(ctx, 2, testActor); !errors.Is(err, repositories.ErrConflict) {
+		t.Errorf("restoring a live product = %v, want ErrConflict", err)
+	}
+}
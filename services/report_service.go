@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"time"
+)
+
+const reportDateLayout = "2006-01-02"
+
+type ReportService struct {
+	repo repositories.ReportStore
+}
+
+func NewReportService(repo repositories.ReportStore) *ReportService {
+	return &ReportService{repo: repo}
+}
+
+// Today reports on sales since local midnight.
+func (s *ReportService) Today(ctx context.Context) (*models.SalesReport, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return s.Range(ctx, today, today)
+}
+
+// Range reports on sales between two calendar days, both inclusive.
+func (s *ReportService) Range(ctx context.Context, start, end time.Time) (*models.SalesReport, error) {
+	if end.Before(start) {
+		return nil, &ValidationError{Fields: map[string]string{"end_date": "must not be before start_date"}}
+	}
+
+	report, err := s.repo.GetSalesSummary(ctx, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	report.StartDate = start.Format(reportDateLayout)
+	report.EndDate = end.Format(reportDateLayout)
+	return report, nil
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"kasir-api/models"
+	"testing"
+	"time"
+)
+
+func TestReportServiceRange(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	checkout := NewTransactionService(store.Transactions(), store.Products(), nil)
+	reports := NewReportService(store.Reports())
+
+	for _, items := range [][]models.CheckoutItem{
+		{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 1}},
+		{{ProductID: 2, Quantity: 4}},
+	} {
+		if _, err := checkout.Checkout(ctx, &models.CheckoutRequest{Items: items}, testActor); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := reports.Today(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.TotalRevenue != 2*3500+5*3000 || report.TotalTransactions != 2 {
+		t.Errorf("revenue %d over %d transactions, want %d over 2",
+			report.TotalRevenue, report.TotalTransactions, 2*3500+5*3000)
+	}
+	if b := report.BestSeller; b == nil || b.ProductID != 2 || b.QuantitySold != 5 {
+		t.Errorf("best seller = %+v, want product 2 with 5 sold", b)
+	}
+
+	now := time.Now()
+	yesterday := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, now.Location())
+	report, err = reports.Range(ctx, yesterday, yesterday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.TotalTransactions != 0 || report.BestSeller != nil {
+		t.Errorf("yesterday's report = %+v, want no sales", report)
+	}
+
+	_, err = reports.Range(ctx, now, yesterday)
+	wantFieldError(t, err, "end_date", "must not be before start_date")
+}
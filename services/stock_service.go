@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log/slog"
+	"strings"
+)
+
+type StockService struct {
+	repo repositories.StockStore
+}
+
+func NewStockService(repo repositories.StockStore) *StockService {
+	return &StockService{repo: repo}
+}
+
+// Adjust books a manual stock movement against a product. Sales are only
+// recorded by checkout.
+func (s *StockService) Adjust(ctx context.Context, productID int, req *models.StockAdjustmentRequest, actor models.Actor) (*models.StockMovement, error) {
+	req.Reason = strings.TrimSpace(req.Reason)
+	if err := validationError(req.Validate()); err != nil {
+		return nil, err
+	}
+
+	m := &models.StockMovement{
+		ProductID: productID,
+		Kind:      req.Kind,
+		Quantity:  req.Delta(),
+		Reason:    req.Reason,
+	}
+	if err := s.repo.Adjust(ctx, m, actor); err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "stock adjusted", "product_id", productID, "kind", m.Kind,
+		"quantity", m.Quantity, "balance", m.Balance)
+	return m, nil
+}
+
+// History returns one page of a product's stock movements and the total.
+func (s *StockService) History(ctx context.Context, productID int, f models.StockMovementFilter) ([]models.StockMovement, int, error) {
+	if err := validationError(f.Validate()); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.History(ctx, productID, f)
+}
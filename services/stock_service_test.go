@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"testing"
+)
+
+func TestStockServiceAdjust(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	stock := NewStockService(store.Stock())
+
+	m, err := stock.Adjust(ctx, 2, &models.StockAdjustmentRequest{Kind: models.StockRestock, Quantity: 24}, testActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Quantity != 24 || m.Balance != 74 || m.Actor != testActor.Username {
+		t.Errorf("restock movement = %+v, want +24 to 74 by %s", m, testActor.Username)
+	}
+
+	req := &models.StockAdjustmentRequest{Kind: models.StockWastage, Quantity: 4, Reason: "  dented  "}
+	if m, err = stock.Adjust(ctx, 2, req, testActor); err != nil {
+		t.Fatal(err)
+	}
+	if m.Quantity != -4 || m.Balance != 70 || m.Reason != "dented" {
+		t.Errorf("wastage movement = %+v, want -4 to 70 with the reason trimmed", m)
+	}
+
+	req = &models.StockAdjustmentRequest{Kind: models.StockAdjustment, Quantity: -71, Reason: "recount"}
+	if _, err := stock.Adjust(ctx, 2, req, testActor); !errors.Is(err, repositories.ErrInsufficientStock) {
+		t.Errorf("adjusting below zero = %v, want ErrInsufficientStock", err)
+	}
+	req = &models.StockAdjustmentRequest{Kind: models.StockRestock, Quantity: 1}
+	if _, err := stock.Adjust(ctx, 99, req, testActor); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("adjusting a missing product = %v, want ErrNotFound", err)
+	}
+
+	_, err = stock.Adjust(ctx, 2, &models.StockAdjustmentRequest{Kind: models.StockSale, Quantity: 1}, testActor)
+	wantFieldError(t, err, "kind", "must be restock, adjustment, return or wastage")
+	_, err = stock.Adjust(ctx, 2, &models.StockAdjustmentRequest{Kind: models.StockAdjustment, Quantity: 5}, testActor)
+	wantFieldError(t, err, "reason", "is required for adjustment")
+
+	p, err := store.Products().GetByID(ctx, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Stock != 70 {
+		t.Errorf("stock = %d, want 70 after the rejected adjustments", p.Stock)
+	}
+}
+
+func TestStockServiceHistory(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	stock := NewStockService(store.Stock())
+	checkout := NewTransactionService(store.Transactions(), store.Products(), nil)
+
+	if _, err := stock.Adjust(ctx, 1, &models.StockAdjustmentRequest{Kind: models.StockRestock, Quantity: 10}, testActor); err != nil {
+		t.Fatal(err)
+	}
+	req := &models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: 1, Quantity: 3}}}
+	trx, err := checkout.Checkout(ctx, req, testActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page := models.ListOptions{Page: 1, Limit: 10}
+	movements, total, err := stock.History(ctx, 1, models.StockMovementFilter{ListOptions: page})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The seeded stock of 100 is the first movement.
+	if total != 3 || len(movements) != 3 {
+		t.Fatalf("history = %d of %d movements, want 3", len(movements), total)
+	}
+	sale := movements[0]
+	if sale.Kind != models.StockSale || sale.Quantity != -3 || sale.Balance != 107 ||
+		sale.TransactionID == nil || *sale.TransactionID != trx.ID {
+		t.Errorf("newest movement = %+v, want the sale of 3 to 107 for transaction %d", sale, trx.ID)
+	}
+
+	movements, total, err = stock.History(ctx, 1, models.StockMovementFilter{ListOptions: page, Kind: models.StockRestock})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || movements[0].Kind != models.StockRestock {
+		t.Errorf("restock history = %+v, want only the restock", movements)
+	}
+
+	_, _, err = stock.History(ctx, 1, models.StockMovementFilter{ListOptions: page, Kind: "theft"})
+	if err == nil {
+		t.Error("History with an unknown kind succeeded")
+	}
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"kasir-api/metrics"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log/slog"
+	"sort"
+)
+
+type TransactionService struct {
+	repo     repositories.TransactionStore
+	products repositories.ProductStore
+	lowStock *LowStockChecker
+}
+
+// NewTransactionService returns the checkout service. lowStock may be nil
+// when low-stock alerts are turned off.
+func NewTransactionService(repo repositories.TransactionStore, products repositories.ProductStore, lowStock *LowStockChecker) *TransactionService {
+	return &TransactionService{repo: repo, products: products, lowStock: lowStock}
+}
+
+// Checkout validates the cart and records the sale. Scanned barcodes are
+// resolved to product IDs first, then lines for the same product are
+// merged so the stock check sees the full requested quantity, and rows are
+// locked in ID order to keep concurrent checkouts deadlock-free.
+func (s *TransactionService) Checkout(ctx context.Context, req *models.CheckoutRequest, actor models.Actor) (*models.Transaction, error) {
+	if len(req.Items) == 0 {
+		return nil, &ValidationError{Fields: map[string]string{"items": "must not be empty"}}
+	}
+
+	fields := make(map[string]string)
+	for i := range req.Items {
+		item := &req.Items[i]
+		switch {
+		case item.Barcode != "" && item.ProductID != 0:
+			fields[fmt.Sprintf("items[%d]", i)] = "give either product_id or barcode, not both"
+		case item.Barcode != "":
+			code, ok := models.NormalizeBarcode(item.Barcode)
+			if !ok {
+				fields[fmt.Sprintf("items[%d].barcode", i)] = "must be a valid EAN-13 or UPC-A code"
+			}
+			item.Barcode = code
+		case item.ProductID <= 0:
+			fields[fmt.Sprintf("items[%d].product_id", i)] = "must be a positive ID"
+		}
+		if item.Quantity <= 0 {
+			fields[fmt.Sprintf("items[%d].quantity", i)] = "must be > 0"
+		}
+	}
+	if err := validationError(fields); err != nil {
+		return nil, err
+	}
+
+	var items []models.CheckoutItem
+	index := make(map[int]int)
+	for _, item := range req.Items {
+		if item.Barcode != "" {
+			p, err := s.products.GetByBarcode(ctx, item.Barcode)
+			if err != nil {
+				return nil, err
+			}
+			item.ProductID, item.Barcode = p.ID, ""
+		}
+		if i, ok := index[item.ProductID]; ok {
+			items[i].Quantity += item.Quantity
+			continue
+		}
+		index[item.ProductID] = len(items)
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ProductID < items[j].ProductID })
+
+	transaction, alerts, err := s.repo.CreateTransaction(ctx, items, actor)
+	if err != nil {
+		return nil, err
+	}
+	if s.lowStock != nil {
+		s.lowStock.Enqueue(ctx, alerts...)
+	}
+	slog.InfoContext(ctx, "checkout completed", "transaction_id", transaction.ID,
+		"total_amount", transaction.TotalAmount, "lines", len(transaction.Details))
+
+	metrics.Checkouts.Inc()
+	metrics.Revenue.Add(float64(transaction.TotalAmount))
+	for _, d := range transaction.Details {
+		metrics.ItemsSold.Add(float64(d.Quantity))
+	}
+	return transaction, nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"testing"
+)
+
+func newTestCheckout(t *testing.T) (*TransactionService, repositories.ProductStore) {
+	t.Helper()
+	store := newTestStore(t)
+	products := store.Products()
+	return NewTransactionService(store.Transactions(), products, nil), products
+}
+
+func wantStock(t *testing.T, products repositories.ProductStore, id, want int) {
+	t.Helper()
+	p, err := products.GetByID(context.Background(), id, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Stock != want {
+		t.Errorf("product %d stock = %d, want %d", id, p.Stock, want)
+	}
+}
+
+func TestCheckoutDecrementsStock(t *testing.T) {
+	checkout, products := newTestCheckout(t)
+	req := &models.CheckoutRequest{Items: []models.CheckoutItem{
+		{ProductID: 2, Quantity: 3},
+		{ProductID: 1, Quantity: 2},
+		{ProductID: 2, Quantity: 1},
+	}}
+	trx, err := checkout.Checkout(context.Background(), req, testActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if trx.TotalAmount != 2*3500+4*3000 {
+		t.Errorf("total = %d, want %d", trx.TotalAmount, 2*3500+4*3000)
+	}
+	if len(trx.Details) != 2 || trx.Details[0].ProductID != 1 || trx.Details[1].Quantity != 4 {
+		t.Errorf("details = %+v, want lines for products 1 and 2 with repeated lines merged", trx.Details)
+	}
+	wantStock(t, products, 1, 98)
+	wantStock(t, products, 2, 46)
+}
+
+func TestCheckoutInsufficientStockSellsNothing(t *testing.T) {
+	checkout, products := newTestCheckout(t)
+	req := &models.CheckoutRequest{Items: []models.CheckoutItem{
+		{ProductID: 1, Quantity: 5},
+		{ProductID: 2, Quantity: 51},
+	}}
+	_, err := checkout.Checkout(context.Background(), req, testActor)
+	if !errors.Is(err, repositories.ErrInsufficientStock) {
+		t.Fatalf("error = %v, want ErrInsufficientStock", err)
+	}
+	wantStock(t, products, 1, 100)
+	wantStock(t, products, 2, 50)
+}
+
+func TestCheckoutRejectsBadCart(t *testing.T) {
+	checkout, _ := newTestCheckout(t)
+	ctx := context.Background()
+
+	_, err := checkout.Checkout(ctx, &models.CheckoutRequest{}, testActor)
+	wantFieldError(t, err, "items", "must not be empty")
+
+	req := &models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: 1, Quantity: 0}}}
+	_, err = checkout.Checkout(ctx, req, testActor)
+	wantFieldError(t, err, "items[0].quantity", "must be > 0")
+
+	req = &models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: 99, Quantity: 1}}}
+	if _, err = checkout.Checkout(ctx, req, testActor); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCheckoutByBarcode(t *testing.T) {
+	checkout, products := newTestCheckout(t)
+	ctx := context.Background()
+
+	// The UPC-A form of Indomie's code and its product ID name the same
+	// product, so the lines merge.
+	req := &models.CheckoutRequest{Items: []models.CheckoutItem{
+		{Barcode: "089686010947", Quantity: 2},
+		{ProductID: 1, Quantity: 1},
+		{Barcode: "8886008101053", Quantity: 1},
+	}}
+	trx, err := checkout.Checkout(ctx, req, testActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trx.Details) != 2 || trx.Details[0].Quantity != 3 {
+		t.Errorf("details = %+v, want 3 of product 1 and 1 of product 2", trx.Details)
+	}
+	wantStock(t, products, 1, 97)
+	wantStock(t, products, 2, 49)
+
+	req = &models.CheckoutRequest{Items: []models.CheckoutItem{
+		{Barcode: "8886008101054", Quantity: 1},
+		{ProductID: 1, Barcode: "8886008101053", Quantity: 1},
+	}}
+	_, err = checkout.Checkout(ctx, req, testActor)
+	wantFieldError(t, err, "items[0].barcode", "must be a valid EAN-13 or UPC-A code")
+	wantFieldError(t, err, "items[1]", "give either product_id or barcode, not both")
+
+	req = &models.CheckoutRequest{Items: []models.CheckoutItem{{Barcode: "4006381333931", Quantity: 1}}}
+	if _, err = checkout.Checkout(ctx, req, testActor); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("unknown barcode = %v, want ErrNotFound", err)
+	}
+}
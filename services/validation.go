@@ -0,0 +1,42 @@
+package services
+
+import (
+	"kasir-api/repositories"
+	"sort"
+	"strings"
+)
+
+// ValidationError reports input that breaks model rules. Fields maps each
+// offending JSON field to a human-readable message.
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + " " + e.Fields[k]
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Unwrap lets callers match any validation failure with
+// errors.Is(err, repositories.ErrValidation).
+func (e *ValidationError) Unwrap() error {
+	return repositories.ErrValidation
+}
+
+// validationError wraps field messages in a *ValidationError, returning nil
+// when there are none so callers can return it directly.
+func validationError(fields map[string]string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: fields}
+}